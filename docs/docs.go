@@ -15,6 +15,75 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/v1/keys/clearkey": {
+            "post": {
+                "description": "EME ClearKey license exchange, used by DASH players. Response is not wrapped into data container. Dev only, served with KEY_SERVER_ENABLED",
+                "tags": [
+                    "Keys"
+                ],
+                "summary": "ClearKey license",
+                "parameters": [
+                    {
+                        "description": "License request",
+                        "name": "LicenseRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_composer_handlers_http_v1_keys.clearKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "License",
+                        "schema": {
+                            "$ref": "#/definitions/internal_composer_handlers_http_v1_keys.clearKeyResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "Error",
+                        "schema": {
+                            "$ref": "#/definitions/render.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/keys/{key_id}": {
+            "get": {
+                "description": "Raw 16-byte content key, used by HLS players (KEYFORMAT=\"identity\"). Dev only, served with KEY_SERVER_ENABLED",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Keys"
+                ],
+                "summary": "Get content key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key ID",
+                        "name": "key_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Content key",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "default": {
+                        "description": "Error",
+                        "schema": {
+                            "$ref": "#/definitions/render.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/tasks/": {
             "post": {
                 "tags": [
@@ -105,6 +174,91 @@ const docTemplate = `{
                 }
             }
         },
+        "/v1/tasks/{task_id}/assets": {
+            "get": {
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "List task assets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Published files",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_asset.Asset"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "Error",
+                        "schema": {
+                            "$ref": "#/definitions/render.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/{task_id}/assets/{path}": {
+            "get": {
+                "description": "Supports Range requests. Assets published over HTTP are redirected to.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Download task asset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Asset path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Asset",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Asset range",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "302": {
+                        "description": "Asset location"
+                    },
+                    "default": {
+                        "description": "Error",
+                        "schema": {
+                            "$ref": "#/definitions/render.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/tasks/{task_id}/cancel/": {
             "post": {
                 "tags": [
@@ -166,9 +320,150 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "github_com_timohahaa_transcoder_internal_composer_modules_asset.Asset": {
+            "type": "object",
+            "properties": {
+                "bitrate": {
+                    "description": "average, bits per second",
+                    "type": "integer"
+                },
+                "codec": {
+                    "description": "RFC 6381 codec string",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "duration": {
+                    "type": "number"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "manifest",
+                        "video",
+                        "audio",
+                        "subtitles",
+                        "poster",
+                        "thumbnails",
+                        "other"
+                    ]
+                },
+                "location": {
+                    "description": "file path, url or s3:// uri",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "relative to the destination",
+                    "type": "string"
+                },
+                "sha256": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "task_id": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.AudioLoudness": {
+            "type": "object",
+            "properties": {
+                "integrated": {
+                    "description": "LUFS",
+                    "type": "number"
+                },
+                "lra": {
+                    "description": "LU",
+                    "type": "number"
+                },
+                "threshold": {
+                    "description": "LUFS",
+                    "type": "number"
+                },
+                "track_num": {
+                    "type": "integer"
+                },
+                "true_peak": {
+                    "description": "dBTP",
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.AudioRendition": {
+            "type": "object",
+            "required": [
+                "bitrate",
+                "codec"
+            ],
+            "properties": {
+                "bitrate": {
+                    "description": "kbit/s",
+                    "type": "integer",
+                    "maximum": 512,
+                    "minimum": 16
+                },
+                "channels": {
+                    "description": "2 by default",
+                    "type": "integer",
+                    "enum": [
+                        1,
+                        2
+                    ]
+                },
+                "codec": {
+                    "type": "string",
+                    "enum": [
+                        "aac",
+                        "he-aac",
+                        "opus"
+                    ]
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.AudioSelection": {
+            "type": "object",
+            "properties": {
+                "default_language": {
+                    "description": "default track, the first one with default disposition (or just the first one) if both are empty",
+                    "type": "string",
+                    "maxLength": 3,
+                    "minLength": 2
+                },
+                "default_stream": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "languages": {
+                    "description": "ISO 639 as tagged in the source",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "streams": {
+                    "description": "source stream indexes",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
         "github_com_timohahaa_transcoder_internal_composer_modules_task.CreateForm": {
             "type": "object",
             "properties": {
+                "destination": {
+                    "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.Destination"
+                },
                 "duration": {
                     "type": "number"
                 },
@@ -183,11 +478,425 @@ const docTemplate = `{
                 }
             }
         },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.Deinterlace": {
+            "type": "object",
+            "properties": {
+                "disabled": {
+                    "description": "interlaced sources are encoded as is",
+                    "type": "boolean"
+                },
+                "filter": {
+                    "description": "bwdif by default",
+                    "type": "string",
+                    "enum": [
+                        "bwdif",
+                        "yadif"
+                    ]
+                },
+                "rate": {
+                    "description": "output frame rate policy, field by default",
+                    "type": "string",
+                    "enum": [
+                        "field",
+                        "frame"
+                    ]
+                },
+                "threshold": {
+                    "description": "share of interlaced frames, 0.5 by default",
+                    "type": "number",
+                    "maximum": 1
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.Destination": {
+            "type": "object",
+            "properties": {
+                "fs": {
+                    "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.DestinationFS"
+                },
+                "http": {
+                    "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.DestinationHTTP"
+                },
+                "s3": {
+                    "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.DestinationS3"
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.DestinationFS": {
+            "type": "object",
+            "required": [
+                "path"
+            ],
+            "properties": {
+                "path": {
+                    "description": "relative to the composer output dir",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.DestinationHTTP": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.DestinationS3": {
+            "type": "object",
+            "required": [
+                "bucket"
+            ],
+            "properties": {
+                "bucket": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.Downmix": {
+            "type": "object",
+            "properties": {
+                "center": {
+                    "description": "0.707 (-3 dB) by default",
+                    "type": "number",
+                    "maximum": 1
+                },
+                "encoding": {
+                    "description": "for matrix surround decoders, none by default",
+                    "type": "string",
+                    "enum": [
+                        "dolby",
+                        "dplii"
+                    ]
+                },
+                "lfe": {
+                    "description": "not mixed by default",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "surround": {
+                    "description": "0.707 (-3 dB) by default",
+                    "type": "number",
+                    "maximum": 1
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.HDR": {
+            "type": "object",
+            "properties": {
+                "codec": {
+                    "description": "of HDR ladder, hevc by default",
+                    "type": "string",
+                    "enum": [
+                        "h264",
+                        "hevc",
+                        "av1"
+                    ]
+                },
+                "passthrough": {
+                    "description": "10-bit ladder keeping HDR next to the tone mapped one",
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.Interlace": {
+            "type": "object",
+            "properties": {
+                "deinterlaced": {
+                    "type": "boolean"
+                },
+                "field_order": {
+                    "description": "of the majority of interlaced frames",
+                    "type": "string",
+                    "enum": [
+                        "tff",
+                        "bff",
+                        "progressive"
+                    ]
+                },
+                "ratio": {
+                    "description": "interlaced frames of classified ones, 0..1",
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.Loudness": {
+            "type": "object",
+            "properties": {
+                "integrated": {
+                    "description": "LUFS, -23 by default",
+                    "type": "number",
+                    "maximum": -5,
+                    "minimum": -70
+                },
+                "lra": {
+                    "description": "LU, 7 by default",
+                    "type": "number",
+                    "maximum": 50,
+                    "minimum": 1
+                },
+                "true_peak": {
+                    "description": "dBTP, -1 by default",
+                    "type": "number",
+                    "maximum": 0,
+                    "minimum": -9
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.MetricSummary": {
+            "type": "object",
+            "properties": {
+                "mean": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "p5": {
+                    "description": "5th percentile",
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.Poster": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "jpg",
+                        "webp"
+                    ]
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "path": {
+                    "description": "relative to the destination",
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.QualityGate": {
+            "type": "object",
+            "properties": {
+                "max_retries": {
+                    "description": "2 by default",
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1
+                },
+                "min_vmaf": {
+                    "type": "number",
+                    "maximum": 100
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.RenditionMetrics": {
+            "type": "object",
+            "properties": {
+                "psnr": {
+                    "description": "luma, dB",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.MetricSummary"
+                        }
+                    ]
+                },
+                "rendition": {
+                    "type": "string"
+                },
+                "ssim": {
+                    "description": "luma",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.MetricSummary"
+                        }
+                    ]
+                },
+                "vmaf": {
+                    "description": "nil if encoders have no libvmaf",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.MetricSummary"
+                        }
+                    ]
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.Result": {
+            "type": "object",
+            "properties": {
+                "interlace": {
+                    "description": "measured on a sample of the source, nil if deinterlacing is disabled or detection failed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.Interlace"
+                        }
+                    ]
+                },
+                "loudness": {
+                    "description": "measured before normalization, only for tracks that are normalized",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.AudioLoudness"
+                    }
+                },
+                "metrics": {
+                    "description": "quality metrics mode only, scores of every video rendition against the source",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.RenditionMetrics"
+                    }
+                },
+                "posters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.Poster"
+                    }
+                },
+                "warnings": {
+                    "description": "non fatal problems, outputs are still published",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "github_com_timohahaa_transcoder_internal_composer_modules_task.Settings": {
             "type": "object",
             "properties": {
+                "audio": {
+                    "description": "audio tracks to keep, all of them if nil",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.AudioSelection"
+                        }
+                    ]
+                },
+                "audio_ladder": {
+                    "description": "renditions of every audio track, single AAC-LC stereo one if empty",
+                    "type": "array",
+                    "maxItems": 8,
+                    "items": {
+                        "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.AudioRendition"
+                    }
+                },
+                "deinterlace": {
+                    "description": "interlaced sources are detected and deinterlaced, defaults if nil",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.Deinterlace"
+                        }
+                    ]
+                },
                 "encrypt": {
                     "type": "boolean"
+                },
+                "encryption_scheme": {
+                    "description": "cenc by default",
+                    "type": "string",
+                    "enum": [
+                        "cenc",
+                        "cbcs"
+                    ]
+                },
+                "hdr": {
+                    "description": "PQ and HLG sources are always tone mapped to SDR BT.709, HDR ladder is added if set",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.HDR"
+                        }
+                    ]
+                },
+                "loudness": {
+                    "description": "EBU R128 normalization of every audio track, disabled if nil",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.Loudness"
+                        }
+                    ]
+                },
+                "packaging": {
+                    "type": "string",
+                    "enum": [
+                        "single-file",
+                        "segmented"
+                    ]
+                },
+                "quality_gate": {
+                    "description": "chunk renditions scoring below VMAF floor are re-encoded, disabled if nil, implies quality metrics",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.QualityGate"
+                        }
+                    ]
+                },
+                "quality_metrics": {
+                    "description": "score every video rendition with VMAF (PSNR/SSIM if encoders have no libvmaf), slows encoding down",
+                    "type": "boolean"
+                },
+                "split": {
+                    "description": "how the source is cut into chunks encoded in parallel, fixed if nil",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.Split"
+                        }
+                    ]
+                },
+                "surround": {
+                    "description": "multichannel sources, defaults if nil",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.Surround"
+                        }
+                    ]
+                },
+                "thumbnails": {
+                    "description": "seek bar preview sprites, disabled if nil",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.Thumbnails"
+                        }
+                    ]
+                },
+                "timing": {
+                    "description": "keyframe cadence, segment and chunk length, 4/4/60 seconds if nil",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.Timing"
+                        }
+                    ]
+                },
+                "video_codecs": {
+                    "description": "ladders encoded next to H.264 one, same qualities, H.264 only if empty",
+                    "type": "array",
+                    "maxItems": 2,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string",
+                        "enum": [
+                            "hevc",
+                            "av1"
+                        ]
+                    }
                 }
             }
         },
@@ -218,21 +927,85 @@ const docTemplate = `{
                 }
             }
         },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.Split": {
+            "type": "object",
+            "properties": {
+                "max_chunk": {
+                    "description": "seconds, scene only, 90 by default",
+                    "type": "number",
+                    "maximum": 600,
+                    "minimum": 10
+                },
+                "min_chunk": {
+                    "description": "seconds, scene only, 30 by default",
+                    "type": "number",
+                    "maximum": 300,
+                    "minimum": 10
+                },
+                "strategy": {
+                    "description": "fixed by default",
+                    "type": "string",
+                    "enum": [
+                        "fixed",
+                        "scene"
+                    ]
+                },
+                "threshold": {
+                    "description": "scene change score, scene only, 0.3 by default",
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.Surround": {
+            "type": "object",
+            "properties": {
+                "bitrate": {
+                    "description": "kbit/s, 384 for 5.1 and 512 for 7.1 aac, 640 for eac3 by default",
+                    "type": "integer",
+                    "maximum": 1024,
+                    "minimum": 128
+                },
+                "codec": {
+                    "description": "aac by default, eac3 is up to 5.1",
+                    "type": "string",
+                    "enum": [
+                        "aac",
+                        "eac3"
+                    ]
+                },
+                "downmix": {
+                    "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.Downmix"
+                },
+                "downmix_only": {
+                    "description": "no multichannel rendition",
+                    "type": "boolean"
+                }
+            }
+        },
         "github_com_timohahaa_transcoder_internal_composer_modules_task.Task": {
             "type": "object",
             "properties": {
+                "destination": {
+                    "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.Destination"
+                },
                 "duration": {
                     "type": "number"
                 },
                 "encoder": {
                     "type": "string"
                 },
+                "error": {
+                    "$ref": "#/definitions/github_com_timohahaa_transcoder_proto_composer.Error"
+                },
                 "file_size": {
                     "type": "integer"
                 },
                 "id": {
                     "type": "string"
                 },
+                "result": {
+                    "$ref": "#/definitions/github_com_timohahaa_transcoder_internal_composer_modules_task.Result"
+                },
                 "routing": {
                     "type": "string"
                 },
@@ -247,6 +1020,128 @@ const docTemplate = `{
                 }
             }
         },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.Thumbnails": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "description": "sprite grid, 10 by default",
+                    "type": "integer",
+                    "maximum": 30,
+                    "minimum": 1
+                },
+                "interval": {
+                    "description": "seconds, 5 by default",
+                    "type": "number",
+                    "maximum": 60,
+                    "minimum": 1
+                },
+                "rows": {
+                    "description": "sprite grid, 10 by default",
+                    "type": "integer",
+                    "maximum": 30,
+                    "minimum": 1
+                },
+                "width": {
+                    "description": "thumbnail width, 160 by default",
+                    "type": "integer",
+                    "maximum": 640,
+                    "minimum": 32
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_internal_composer_modules_task.Timing": {
+            "type": "object",
+            "required": [
+                "gop",
+                "segment"
+            ],
+            "properties": {
+                "chunk": {
+                    "description": "seconds, fixed split only, 60 rounded up to segments by default",
+                    "type": "integer",
+                    "maximum": 600,
+                    "minimum": 10
+                },
+                "gop": {
+                    "description": "seconds",
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1
+                },
+                "renditions": {
+                    "description": "GOP seconds by rendition name (360, 1080_hevc), task one for the rest",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "segment": {
+                    "description": "fragment/segment seconds",
+                    "type": "integer",
+                    "maximum": 20,
+                    "minimum": 1
+                }
+            }
+        },
+        "github_com_timohahaa_transcoder_proto_composer.Error": {
+            "type": "object",
+            "properties": {
+                "domain": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_composer_handlers_http_v1_keys.clearKeyJWK": {
+            "type": "object",
+            "properties": {
+                "k": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_composer_handlers_http_v1_keys.clearKeyRequest": {
+            "type": "object",
+            "properties": {
+                "kids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_composer_handlers_http_v1_keys.clearKeyResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_composer_handlers_http_v1_keys.clearKeyJWK"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
         "internal_composer_handlers_http_v1_tasks.taskProgress": {
             "type": "object",
             "properties": {
@@ -8,8 +8,11 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
+	"github.com/timohahaa/transcoder/internal/composer/modules/asset"
+	"github.com/timohahaa/transcoder/internal/composer/modules/drm"
 	"github.com/timohahaa/transcoder/internal/composer/modules/task"
 	"github.com/timohahaa/transcoder/pkg/errors"
+	"github.com/timohahaa/transcoder/pkg/storage"
 	pb "github.com/timohahaa/transcoder/proto/composer"
 )
 
@@ -28,12 +31,17 @@ type (
 	}
 
 	mod struct {
-		task *task.Module
+		task  *task.Module
+		drm   *drm.Module
+		asset *asset.Module
 	}
 
 	Config struct {
-		HttpAddr string
-		WorkDir  string
+		HttpAddr     string
+		WorkDir      string
+		OutputDir    string
+		KeyServerURL string
+		S3           storage.S3Config
 	}
 )
 
@@ -41,12 +49,13 @@ func New(
 	conn *pgxpool.Pool,
 	redis redis.UniversalClient,
 	cfg Config,
-) *Assembler {
-	return &Assembler{
+) (*Assembler, error) {
+	var a = &Assembler{
 		l:   log.WithFields(log.Fields{"mod": "assembler"}),
 		cfg: cfg,
 		mod: mod{
 			task: task.New(conn, redis),
+			drm:  drm.New(conn),
 		},
 		tasks: make(chan task.Task),
 
@@ -56,7 +65,18 @@ func New(
 		watcherDone: make(chan struct{}),
 		once:        sync.Once{},
 	}
+
+	var err error
+	if a.mod.asset, err = asset.New(conn, asset.Config{
+		OutputDir: cfg.OutputDir,
+		S3:        cfg.S3,
+	}); err != nil {
+		return nil, err
+	}
+
+	return a, nil
 }
+
 func (a *Assembler) Run(workers, watchers int) {
 	for i := range watchers {
 		a.watcherWG.Add(1)
@@ -0,0 +1,100 @@
+package assembler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/timohahaa/transcoder/internal/composer/modules/asset"
+)
+
+// describeAssets lists every file of assetsDir,
+// files of renditions get media info of the rendition
+func describeAssets(assetsDir string, videos, audios []rendition) ([]asset.Asset, error) {
+	type owner struct {
+		kind string
+		r    rendition
+	}
+
+	var owners = make(map[string]owner)
+	for kind, renditions := range map[string][]rendition{
+		asset.KindVideo: videos,
+		asset.KindAudio: audios,
+	} {
+		for _, r := range renditions {
+			owners[r.Path] = owner{kind: kind, r: r}
+			for _, seg := range r.Segments {
+				owners[seg] = owner{kind: kind, r: r}
+			}
+		}
+	}
+
+	var assets []asset.Asset
+	err := filepath.WalkDir(assetsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		key, err := filepath.Rel(assetsDir, path)
+		if err != nil {
+			return err
+		}
+		key = filepath.ToSlash(key)
+
+		var a = asset.Asset{
+			Path: key,
+			Kind: asset.KindOther,
+		}
+		if a.Size, a.SHA256, err = hashFile(path); err != nil {
+			return err
+		}
+
+		if o, ok := owners[path]; ok {
+			var stream = firstStream(o.r.Info)
+			a.Kind = o.kind
+			a.Codec = o.r.Index.Codec
+			a.BitRate = o.r.avgBitrate()
+			a.Duration = o.r.Index.Duration()
+			if o.kind == asset.KindVideo {
+				a.Width = stream.Width
+				a.Height = stream.Height
+			}
+		} else {
+			switch {
+			case isManifest(key):
+				a.Kind = asset.KindManifest
+			case strings.HasPrefix(key, subtitlesDir+"/"):
+				a.Kind = asset.KindSubtitles
+			case strings.HasPrefix(key, "poster"):
+				a.Kind = asset.KindPoster
+			case strings.HasPrefix(key, thumbnailsDir+"/"):
+				a.Kind = asset.KindThumbnails
+			}
+		}
+
+		assets = append(assets, a)
+		return nil
+	})
+
+	return assets, err
+}
+
+func hashFile(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	var h = sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,214 @@
+package assembler
+
+import (
+	"fmt"
+
+	"github.com/timohahaa/transcoder/pkg/dash"
+	"github.com/timohahaa/transcoder/pkg/ffmpeg"
+)
+
+const dashManifest = "manifest.mpd"
+
+// writes static MPD: one adaptation set per video codec,
+// one adaptation set per audio track, codec and channels, one per subtitle track
+func writeDASH(
+	dstDir string,
+	videos, audios []rendition,
+	subtitles []subtitle,
+	prot *protection,
+	minBufferTime float64,
+) (string, error) {
+	var (
+		profile  = dash.ProfileOnDemand
+		duration float64
+		period   = dash.Period{ID: "0"}
+	)
+
+	// on-demand profile requires sidx for every representation,
+	// live profile requires segment templates for every representation
+	for _, r := range append(videos, audios...) {
+		switch {
+		case r.segmented():
+			profile = dash.ProfileLive
+		case r.Index.Sidx == nil:
+			profile = dash.ProfileMain
+		}
+		duration = max(duration, r.Index.Duration())
+	}
+
+	// renditions of different codecs are not switchable
+	var videoSets = make(map[string]int, len(videos))
+	for _, v := range videos {
+		var (
+			stream        = v.Info.GetHighestVideo()
+			width, height = stream.GetResolution()
+			repr          = representation(v)
+		)
+
+		repr.Width = width
+		repr.Height = height
+		repr.FrameRate = stream.RFrameRate
+		repr.SAR = "1:1"
+
+		i, ok := videoSets[v.Ladder]
+		if !ok {
+			i = len(period.AdaptationSets)
+			videoSets[v.Ladder] = i
+			period.AdaptationSets = append(period.AdaptationSets, dash.AdaptationSet{
+				ID:                      i,
+				ContentType:             dash.ContentTypeVideo,
+				MimeType:                dash.MimeTypeVideo,
+				SegmentAlignment:        true,
+				SubsegmentAlignment:     true,
+				SubsegmentStartsWithSAP: 1,
+				StartWithSAP:            1,
+				ContentProtections:      prot.dashContentProtections(v),
+			})
+
+			// HDR renditions have ladders of their own, so the set has a single transfer
+			if primaries, transfer, matrix, ok := hdrCICP(stream); ok {
+				var set = &period.AdaptationSets[i]
+				set.EssentialProperties, set.SupplementalProperties = dash.ColorProperties(primaries, transfer, matrix)
+			}
+		}
+
+		var set = &period.AdaptationSets[i]
+		set.MaxWidth = max(set.MaxWidth, width)
+		set.MaxHeight = max(set.MaxHeight, height)
+		set.Representations = append(set.Representations, repr)
+	}
+
+	// renditions of a track with the same codec and channels are switchable
+	var audioSets = make(map[string]int, len(audios))
+	for _, a := range audios {
+		var (
+			stream = firstStream(a.Info)
+			repr   = representation(a)
+			tags   = a.audioTags()
+			num, _ = renditionOrder(a.Name)
+			key    = fmt.Sprintf("%d/%s/%d", num, a.Index.Codec, stream.Channels)
+		)
+
+		repr.AudioSamplingRate = stream.SampleRate
+		switch a.Index.Codec {
+		case "ac-3", "ec-3":
+			repr.AudioChannelConfiguration = dash.DolbyAudioChannels(stream.Channels)
+		default:
+			repr.AudioChannelConfiguration = dash.AudioChannels(stream.Channels)
+		}
+
+		if i, ok := audioSets[key]; ok {
+			var set = &period.AdaptationSets[i]
+			set.Representations = append(set.Representations, repr)
+			continue
+		}
+
+		var set = dash.AdaptationSet{
+			ID:                      len(period.AdaptationSets),
+			ContentType:             dash.ContentTypeAudio,
+			MimeType:                dash.MimeTypeAudio,
+			Lang:                    tags.Language,
+			Label:                   tags.Label,
+			SegmentAlignment:        true,
+			SubsegmentAlignment:     true,
+			SubsegmentStartsWithSAP: 1,
+			StartWithSAP:            1,
+			ContentProtections:      prot.dashContentProtections(a),
+		}
+		if tags.Default {
+			set.Roles = append(set.Roles, dash.Role("main"))
+		} else {
+			set.Roles = append(set.Roles, dash.Role("alternate"))
+		}
+
+		set.Representations = append(set.Representations, repr)
+		audioSets[key] = len(period.AdaptationSets)
+		period.AdaptationSets = append(period.AdaptationSets, set)
+	}
+
+	// sidecar WebVTT files, not segmented
+	for _, s := range subtitles {
+		var set = dash.AdaptationSet{
+			ID:          len(period.AdaptationSets),
+			ContentType: dash.ContentTypeText,
+			MimeType:    dash.MimeTypeWebVTT,
+			Lang:        s.Language,
+			Roles:       []dash.Descriptor{dash.Role("subtitle")},
+			Representations: []dash.Representation{{
+				ID:        s.name(),
+				Bandwidth: s.bitrate(),
+				BaseURL:   s.uri(),
+			}},
+		}
+		if s.Forced {
+			set.Roles = append(set.Roles, dash.Role("forced-subtitle"))
+		}
+
+		period.AdaptationSets = append(period.AdaptationSets, set)
+	}
+
+	var mpd = dash.NewMPD(profile, duration, minBufferTime)
+	if prot != nil {
+		mpd.Protected()
+	}
+	mpd.Periods = append(mpd.Periods, period)
+
+	return dash.Write(mpd, dstDir, dashManifest)
+}
+
+// representation addressed by segment template for segmented packaging,
+// by sidx if present, by explicit byte-ranges otherwise
+func representation(r rendition) dash.Representation {
+	var repr = dash.Representation{
+		ID:        r.Name,
+		Bandwidth: r.peakBitrate(),
+		Codecs:    r.Index.Codec,
+	}
+
+	if r.segmented() {
+		repr.SegmentTemplate = &dash.SegmentTemplate{
+			Timescale:       r.Index.Timescale,
+			Initialization:  r.uri(r.Path),
+			Media:           r.uri(dash.NumberTemplate(ffmpeg.SegmentNamePattern)),
+			StartNumber:     ffmpeg.SegmentStartNumber,
+			SegmentTimeline: timeline(r),
+		}
+		return repr
+	}
+
+	repr.BaseURL = r.uri(r.Path)
+
+	var init = dash.Initialization{
+		Range: dash.Range(r.Index.Init.Offset, r.Index.Init.Length),
+	}
+
+	if r.Index.Sidx != nil {
+		repr.SegmentBase = &dash.SegmentBase{
+			Timescale:      r.Index.Timescale,
+			IndexRange:     dash.Range(r.Index.Sidx.Offset, r.Index.Sidx.Length),
+			Initialization: init,
+		}
+		return repr
+	}
+
+	repr.SegmentList = &dash.SegmentList{
+		Timescale:       r.Index.Timescale,
+		Initialization:  init,
+		SegmentTimeline: timeline(r),
+	}
+	for _, f := range r.Index.Fragments {
+		repr.SegmentList.SegmentURLs = append(repr.SegmentList.SegmentURLs, dash.SegmentURL{
+			MediaRange: dash.Range(f.Offset, f.Length),
+		})
+	}
+
+	return repr
+}
+
+func timeline(r rendition) *dash.SegmentTimeline {
+	var t = &dash.SegmentTimeline{S: make([]dash.S, 0, len(r.Index.Fragments))}
+	for _, f := range r.Index.Fragments {
+		t.S = append(t.S, dash.S{T: f.DecodeTime, D: f.Duration})
+	}
+	return t
+}
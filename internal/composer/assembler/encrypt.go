@@ -0,0 +1,157 @@
+package assembler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"strings"
+
+	"github.com/timohahaa/transcoder/internal/composer/modules/drm"
+	"github.com/timohahaa/transcoder/internal/composer/modules/task"
+	"github.com/timohahaa/transcoder/pkg/dash"
+	"github.com/timohahaa/transcoder/pkg/hls"
+	"github.com/timohahaa/transcoder/pkg/mp4encrypt"
+)
+
+// content protection of the task outputs, nil if task is not encrypted
+type protection struct {
+	Key        drm.Key
+	KeyURL     string // raw key delivery, HLS
+	LicenseURL string // ClearKey license server, DASH
+}
+
+func (a *Assembler) protection(ctx context.Context, t task.Task) (*protection, error) {
+	key, err := a.mod.drm.Create(ctx, t.ID, t.Settings.Scheme())
+	if err != nil {
+		return nil, err
+	}
+
+	var baseURL = strings.TrimSuffix(a.cfg.KeyServerURL, "/")
+	return &protection{
+		Key:        key,
+		KeyURL:     baseURL + "/" + key.KeyID.String(),
+		LicenseURL: baseURL + "/clearkey",
+	}, nil
+}
+
+// encrypts packaged output in place
+func encrypt(ctx context.Context, out packaged, key drm.Key) error {
+	var params = mp4encrypt.Params{
+		Scheme: key.Scheme,
+		KeyID:  key.KeyID[:],
+		Key:    key.Key,
+		IV:     key.IV,
+		PSSH:   []string{mp4encrypt.SystemIDClearKey},
+	}
+
+	if !out.segmented() {
+		return encryptFile(out.Path, func(dst string) error {
+			p, err := runParams(params)
+			if err != nil {
+				return err
+			}
+			return mp4encrypt.Encrypt(ctx, out.Path, dst, p)
+		})
+	}
+
+	// media segments need clear init segment for track info, so it goes last
+	for _, seg := range out.Segments {
+		err := encryptFile(seg, func(dst string) error {
+			p, err := runParams(params)
+			if err != nil {
+				return err
+			}
+			return mp4encrypt.EncryptFragments(ctx, out.Path, seg, dst, p)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return encryptFile(out.Path, func(dst string) error {
+		p, err := runParams(params)
+		if err != nil {
+			return err
+		}
+		return mp4encrypt.Encrypt(ctx, out.Path, dst, p)
+	})
+}
+
+// every mp4encrypt run counts cenc IVs up from the given one, so runs sharing
+// the key and the IV would reuse AES-CTR keystream across tracks and segments,
+// each run gets its own random IV, players read them from senc boxes,
+// cbcs keeps the constant IV of the key, HLS playlists carry it
+func runParams(p mp4encrypt.Params) (mp4encrypt.Params, error) {
+	if p.Scheme != mp4encrypt.SchemeCENC {
+		return p, nil
+	}
+
+	var iv = make([]byte, len(p.IV))
+	if _, err := rand.Read(iv); err != nil {
+		return p, err
+	}
+	p.IV = iv
+	return p, nil
+}
+
+func encryptFile(path string, fn func(dst string) error) error {
+	var tmp = path + ".enc"
+	if err := fn(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (p *protection) hlsKeys() []hls.Key {
+	if p == nil {
+		return nil
+	}
+
+	var key = hls.Key{
+		Method:            hls.KeyMethodSampleAESCTR,
+		URI:               p.KeyURL,
+		KeyFormat:         hls.KeyFormatIdentity,
+		KeyFormatVersions: "1",
+	}
+	// cenc IVs are stored per sample in senc boxes
+	if p.Key.Scheme == mp4encrypt.SchemeCBCS {
+		key.Method = hls.KeyMethodSampleAES
+		key.IV = p.Key.IV
+	}
+
+	return []hls.Key{key}
+}
+
+func (p *protection) dashContentProtections(r rendition) []dash.ContentProtection {
+	if p == nil {
+		return nil
+	}
+
+	var (
+		cp = []dash.ContentProtection{
+			{
+				SchemeIDURI: dash.SchemeMP4Protection,
+				Value:       p.Key.Scheme,
+				DefaultKID:  p.Key.KeyID.String(),
+			},
+			{
+				SchemeIDURI: dash.SchemeClearKey,
+				Value:       "ClearKey1.0",
+				LicenseURL:  p.LicenseURL,
+			},
+		}
+	)
+
+	for _, pssh := range r.Index.PSSH {
+		if pssh.SystemID == mp4encrypt.SystemIDClearKey {
+			cp = append(cp, dash.ContentProtection{
+				SchemeIDURI: dash.SchemeW3CCommon,
+				PSSH:        base64.StdEncoding.EncodeToString(pssh.Box),
+			})
+		}
+	}
+
+	return cp
+}
@@ -41,7 +41,8 @@ func (a *Assembler) findQualityChunks(taskDir string) (map[string][]string, erro
 		}
 
 		for _, de := range dirEntries {
-			if de.IsDir() {
+			// unfinished uploads
+			if de.IsDir() || strings.HasPrefix(de.Name(), ".") {
 				continue
 			}
 
@@ -0,0 +1,244 @@
+package assembler
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/timohahaa/transcoder/pkg/hls"
+)
+
+const (
+	hlsMasterPlaylist = "master.m3u8"
+	hlsAudioGroupID   = "audio"
+	hlsSubtitlesGroup = "subtitles"
+)
+
+func (r rendition) playlistName() string {
+	return r.Name + ".m3u8"
+}
+
+// audio renditions of the same ladder rung of every track,
+// every video variant is listed once per group
+type hlsAudioGroup struct {
+	ID            string
+	Renditions    []rendition
+	Codecs        []string
+	Peak, Average int64
+}
+
+func audioGroupID(r rendition) string {
+	if r.Ladder == "" {
+		return hlsAudioGroupID
+	}
+	return hlsAudioGroupID + "_" + r.Ladder
+}
+
+// groups audios by ladder rung, a track missing from a group (stereo only track
+// in the surround group, etc.) is represented there by its first rendition,
+// so every language is available whatever group a player picks
+func audioGroups(audios []rendition) []*hlsAudioGroup {
+	var (
+		groups []*hlsAudioGroup
+		tracks []rendition // first rendition of every track
+	)
+
+	for _, a := range audios {
+		var (
+			id = audioGroupID(a)
+			i  = slices.IndexFunc(groups, func(g *hlsAudioGroup) bool { return g.ID == id })
+		)
+		if i < 0 {
+			i = len(groups)
+			groups = append(groups, &hlsAudioGroup{ID: id})
+		}
+		groups[i].Renditions = append(groups[i].Renditions, a)
+
+		if !slices.ContainsFunc(tracks, func(t rendition) bool { return sameTrack(t, a) }) {
+			tracks = append(tracks, a)
+		}
+	}
+
+	for _, g := range groups {
+		for _, t := range tracks {
+			if !slices.ContainsFunc(g.Renditions, func(r rendition) bool { return sameTrack(r, t) }) {
+				g.Renditions = append(g.Renditions, t)
+			}
+		}
+
+		for _, r := range g.Renditions {
+			if !slices.Contains(g.Codecs, r.Index.Codec) {
+				g.Codecs = append(g.Codecs, r.Index.Codec)
+			}
+			g.Peak = max(g.Peak, r.peakBitrate())
+			g.Average = max(g.Average, r.avgBitrate())
+		}
+	}
+
+	return groups
+}
+
+func sameTrack(a, b rendition) bool {
+	var (
+		aNum, _ = renditionOrder(a.Name)
+		bNum, _ = renditionOrder(b.Name)
+	)
+	return aNum == bNum
+}
+
+// writes master playlist and media playlists for every video rendition,
+// audio and subtitle track
+func writeHLS(dstDir string, videos, audios []rendition, subtitles []subtitle, prot *protection) (string, error) {
+	var (
+		master = hls.MasterPlaylist{IndependentSegments: true}
+		groups = audioGroups(audios)
+	)
+
+	for _, a := range audios {
+		if _, err := hls.Write(mediaPlaylist(a, prot), dstDir, a.playlistName()); err != nil {
+			return "", err
+		}
+	}
+
+	for _, g := range groups {
+		var names = make(uniqueNames, len(g.Renditions))
+		for _, a := range g.Renditions {
+			var (
+				stream = firstStream(a.Info)
+				tags   = a.audioTags()
+			)
+
+			master.Media = append(master.Media, hls.Media{
+				Type:       hls.MediaTypeAudio,
+				GroupID:    g.ID,
+				Name:       names.get(tags.Label),
+				Language:   tags.Language,
+				Default:    tags.Default,
+				Autoselect: true,
+				Channels:   strconv.Itoa(stream.Channels),
+				URI:        a.playlistName(),
+			})
+		}
+	}
+
+	var (
+		subtitleNames   = make(uniqueNames, len(subtitles))
+		defaultSubtitle = -1
+	)
+	for i, s := range subtitles {
+		if s.Default && defaultSubtitle < 0 {
+			defaultSubtitle = i
+		}
+	}
+	for i, s := range subtitles {
+		var playlist = s.name() + ".m3u8"
+		if _, err := hls.Write(subtitlePlaylist(s), filepath.Join(dstDir, subtitlesDir), playlist); err != nil {
+			return "", err
+		}
+
+		master.Media = append(master.Media, hls.Media{
+			Type:       hls.MediaTypeSubtitles,
+			GroupID:    hlsSubtitlesGroup,
+			Name:       subtitleNames.get(s.label()),
+			Language:   s.Language,
+			Default:    i == defaultSubtitle,
+			Autoselect: true,
+			Forced:     s.Forced,
+			URI:        subtitlesDir + "/" + playlist,
+		})
+	}
+
+	// no audio group is the same as one empty group
+	if len(groups) == 0 {
+		groups = []*hlsAudioGroup{nil}
+	}
+
+	for _, v := range videos {
+		if _, err := hls.Write(mediaPlaylist(v, prot), dstDir, v.playlistName()); err != nil {
+			return "", err
+		}
+	}
+
+	for _, g := range groups {
+		for _, v := range videos {
+			var (
+				stream        = v.Info.GetHighestVideo()
+				width, height = stream.GetResolution()
+				variant       = hls.Variant{
+					Bandwidth:        v.peakBitrate(),
+					AverageBandwidth: v.avgBitrate(),
+					Codecs:           []string{v.Index.Codec},
+					Width:            width,
+					Height:           height,
+					FrameRate:        frameRate(stream),
+					VideoRange:       videoRange(stream),
+					URI:              v.playlistName(),
+				}
+			)
+
+			if g != nil {
+				variant.Bandwidth += g.Peak
+				variant.AverageBandwidth += g.Average
+				variant.Audio = g.ID
+				variant.Codecs = append(variant.Codecs, g.Codecs...)
+			}
+			if len(subtitles) > 0 {
+				variant.Subtitles = hlsSubtitlesGroup
+			}
+
+			master.Variants = append(master.Variants, variant)
+		}
+	}
+
+	return hls.Write(master, dstDir, hlsMasterPlaylist)
+}
+
+// segments are addressed by byte-ranges in single fragmented file
+// or by separate files for segmented packaging
+func mediaPlaylist(r rendition, prot *protection) hls.MediaPlaylist {
+	var p = hls.MediaPlaylist{
+		PlaylistType:        hls.PlaylistTypeVOD,
+		IndependentSegments: true,
+		Keys:                prot.hlsKeys(),
+		Map:                 hls.Map{URI: r.uri(r.Path)},
+		Segments:            make([]hls.Segment, 0, len(r.Index.Fragments)),
+	}
+
+	if r.segmented() {
+		for i, f := range r.Index.Fragments {
+			p.Segments = append(p.Segments, hls.Segment{
+				Duration: r.Index.Seconds(f.Duration),
+				URI:      r.uri(r.Segments[i]),
+			})
+		}
+		return p
+	}
+
+	p.Map.ByteRange = &hls.ByteRange{
+		Length: r.Index.Init.Length,
+		Offset: r.Index.Init.Offset,
+	}
+	for _, f := range r.Index.Fragments {
+		p.Segments = append(p.Segments, hls.Segment{
+			Duration: r.Index.Seconds(f.Duration),
+			URI:      p.Map.URI,
+			ByteRange: &hls.ByteRange{
+				Length: f.Length,
+				Offset: f.Offset,
+			},
+		})
+	}
+
+	return p
+}
+
+// whole WebVTT file is a single segment next to the playlist
+func subtitlePlaylist(s subtitle) hls.MediaPlaylist {
+	return hls.MediaPlaylist{
+		PlaylistType: hls.PlaylistTypeVOD,
+		Segments: []hls.Segment{{
+			Duration: s.Duration,
+			URI:      filepath.Base(s.Path),
+		}},
+	}
+}
@@ -0,0 +1,249 @@
+package assembler
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/timohahaa/transcoder/internal/composer/modules/meta"
+	"github.com/timohahaa/transcoder/pkg/consts"
+	"github.com/timohahaa/transcoder/pkg/ffprobe"
+	"github.com/timohahaa/transcoder/pkg/mp4"
+)
+
+// packaged output (video rendition or audio track) with everything
+// needed to describe it in manifests
+type rendition struct {
+	Name     string
+	Path     string   // fragmented file or init segment
+	Segments []string // media segments, segmented packaging only
+	Index    *mp4.Index
+	Info     *ffprobe.Info
+	Track    *meta.Audio // audio only, nil if the task has no split meta
+	Ladder   string      // audio ladder rendition name or video codec, empty for a single rendition track and H.264
+}
+
+// names of encoded files of the track (without extension) in ladder order:
+// audio_<track num> for unnamed rendition, audio_<track num>_<rendition> otherwise
+func audioNames(t meta.Audio) []string {
+	var base = "audio_" + strconv.Itoa(t.TrackNum)
+	if t.Preset == nil || len(t.Preset.Renditions) == 0 {
+		return []string{base}
+	}
+
+	var names = make([]string, 0, len(t.Preset.Renditions))
+	for _, r := range t.Preset.Renditions {
+		if r.Name == "" {
+			names = append(names, base)
+			continue
+		}
+		names = append(names, base+"_"+r.Name)
+	}
+	return names
+}
+
+// attaches split meta of audio tracks to renditions,
+// renditions of every track are ordered as in the ladder
+func attachTracks(renditions []rendition, tracks []meta.Audio) {
+	var order = make(map[string]int, len(renditions))
+	for j := range tracks {
+		for _, name := range audioNames(tracks[j]) {
+			order[name] = len(order)
+			for i := range renditions {
+				if renditions[i].Name == name {
+					renditions[i].Track = &tracks[j]
+				}
+			}
+		}
+	}
+
+	// unknown to the meta go last
+	var pos = func(r rendition) int {
+		if p, ok := order[r.Name]; ok {
+			return p
+		}
+		return len(order)
+	}
+	slices.SortStableFunc(renditions, func(a, b rendition) int {
+		return pos(a) - pos(b)
+	})
+}
+
+type audioTags struct {
+	Language string // empty if unknown
+	Label    string // human readable name for players
+	Default  bool
+}
+
+// tags of the audio track, taken from the encoded file if there is no split meta
+func (r rendition) audioTags() audioTags {
+	if t := r.Track; t != nil {
+		var label = t.Title
+		if label == "" {
+			label = t.Language
+		}
+		if label == "" {
+			label = "audio_" + strconv.Itoa(t.TrackNum)
+		}
+		return audioTags{Language: t.Language, Label: label, Default: t.Default}
+	}
+
+	var language = firstStream(r.Info).Tags.Language
+	if language == "und" {
+		language = ""
+	}
+	var trackNum, _ = renditionOrder(r.Name)
+	return audioTags{Language: language, Label: r.Name, Default: trackNum == 0}
+}
+
+// keeps names unique within a group, repeated ones get a number
+type uniqueNames map[string]int
+
+func (n uniqueNames) get(name string) string {
+	n[name]++
+	if c := n[name]; c > 1 {
+		return fmt.Sprintf("%s (%d)", name, c)
+	}
+	return name
+}
+
+func readRenditions(ctx context.Context, outputs map[string]packaged) ([]rendition, error) {
+	var res = make([]rendition, 0, len(outputs))
+
+	for name, out := range outputs {
+		var (
+			idx *mp4.Index
+			err error
+		)
+		if out.segmented() {
+			idx, err = mp4.ReadSegmentedIndex(out.Path, out.Segments)
+		} else {
+			idx, err = mp4.ReadIndex(out.Path)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// init segment has no samples to probe frame rate from,
+		// packaging copies streams so source has the same parameters
+		info, err := ffprobe.GetInfo(ctx, out.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		var _, ladder = renditionOrder(name)
+		res = append(res, rendition{
+			Name:     name,
+			Path:     out.Path,
+			Segments: out.Segments,
+			Index:    idx,
+			Info:     info,
+			Ladder:   ladder,
+		})
+	}
+
+	// lowest quality first, audios by track number
+	slices.SortFunc(res, func(a, b rendition) int { return compareRenditions(a.Name, b.Name) })
+
+	return res, nil
+}
+
+func compareRenditions(a, b string) int {
+	aNum, aRest := renditionOrder(a)
+	bNum, bRest := renditionOrder(b)
+	if aNum != bNum {
+		return aNum - bNum
+	}
+	return strings.Compare(aRest, bRest)
+}
+
+// splits quality or audio_<track num>[_<rendition>] name into number and the rest
+func renditionOrder(name string) (int, string) {
+	num, rest, _ := strings.Cut(strings.TrimPrefix(name, "audio_"), "_")
+	n, _ := strconv.Atoi(num)
+	return n, rest
+}
+
+func (r rendition) segmented() bool {
+	return len(r.Segments) > 0
+}
+
+// uri of the file relative to manifests
+func (r rendition) uri(path string) string {
+	if r.segmented() {
+		return r.Name + "/" + filepath.Base(path)
+	}
+	return filepath.Base(path)
+}
+
+// bandwidth of the heaviest segment, bits per second
+func (r rendition) peakBitrate() int64 {
+	var peak float64
+	for _, f := range r.Index.Fragments {
+		if dur := r.Index.Seconds(f.Duration); dur > 0 {
+			peak = max(peak, float64(f.Length*8)/dur)
+		}
+	}
+	return int64(peak)
+}
+
+func (r rendition) avgBitrate() int64 {
+	if dur := r.Index.Duration(); dur > 0 {
+		return int64(float64(r.Index.Size()*8) / dur)
+	}
+	return 0
+}
+
+// HLS VIDEO-RANGE of PQ and HLG renditions, empty for SDR ones
+func videoRange(s ffprobe.Stream) string {
+	switch s.ColorTransfer {
+	case consts.TransferPQ:
+		return "PQ"
+	case consts.TransferHLG:
+		return "HLG"
+	default:
+		return ""
+	}
+}
+
+// ISO/IEC 23001-8 code points of HDR renditions, ok is false for SDR ones
+func hdrCICP(s ffprobe.Stream) (primaries, transfer, matrix int, ok bool) {
+	switch s.ColorTransfer {
+	case consts.TransferPQ:
+		return 9, 16, 9, true
+	case consts.TransferHLG:
+		return 9, 18, 9, true
+	default:
+		return 0, 0, 0, false
+	}
+}
+
+func firstStream(info *ffprobe.Info) ffprobe.Stream {
+	if info == nil || len(info.Streams) == 0 {
+		return ffprobe.Stream{}
+	}
+	return info.Streams[0]
+}
+
+// precise frame rate, ffprobe.Stream.GetFrameRate rounds to 2 digits
+func frameRate(s ffprobe.Stream) float64 {
+	var parts = strings.Split(s.RFrameRate, "/")
+	if len(parts) != 2 {
+		fps, _ := s.GetFrameRate()
+		return fps
+	}
+
+	num, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0
+	}
+	den, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || den == 0 {
+		return 0
+	}
+
+	return num / den
+}
@@ -0,0 +1,47 @@
+package assembler
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/timohahaa/transcoder/internal/composer/modules/task"
+	"github.com/timohahaa/transcoder/pkg/ffmpeg"
+)
+
+// output of packaging single video rendition or audio track
+type packaged struct {
+	Source   string   // stitched video or encoded audio
+	Path     string   // fragmented file or init segment
+	Segments []string // media segments, segmented packaging only
+}
+
+func (p packaged) segmented() bool {
+	return len(p.Segments) > 0
+}
+
+// single-file: dstDir/<name>_frag.mp4
+// segmented:   dstDir/<name>/init.mp4 + dstDir/<name>/seg_NNNNN.m4s
+func pack(ctx context.Context, settings task.Settings, src, dstDir, name string) (packaged, error) {
+	var segmentSeconds = settings.KeyframeTiming().Segment
+
+	if settings.Segmented() {
+		segments, err := ffmpeg.Segment(ctx, src, filepath.Join(dstDir, name), segmentSeconds)
+		if err != nil {
+			return packaged{}, err
+		}
+
+		return packaged{
+			Source:   src,
+			Path:     segments.Init,
+			Segments: segments.Media,
+		}, nil
+	}
+
+	out, err := ffmpeg.Fragment(ctx, src, dstDir, fmt.Sprintf("%v_frag.mp4", name), segmentSeconds)
+	if err != nil {
+		return packaged{}, err
+	}
+
+	return packaged{Source: src, Path: out}, nil
+}
@@ -0,0 +1,86 @@
+package assembler
+
+import (
+	"context"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"os"
+	"path/filepath"
+
+	"github.com/timohahaa/transcoder/internal/composer/modules/task"
+	"github.com/timohahaa/transcoder/pkg/ffmpeg"
+)
+
+var (
+	// variants wider than the source poster are skipped, it is never upscaled
+	posterWidths  = []int{1280, 640, 320}
+	posterFormats = []string{"jpg", "webp"}
+)
+
+// writePosters publishes the source poster as is (poster.jpg)
+// and scaled down variants next to it: poster_<width>w.<format>
+func writePosters(ctx context.Context, src, assetsDir string) ([]task.Poster, error) {
+	var original = filepath.Join(assetsDir, "poster.jpg")
+	if err := os.Rename(src, original); err != nil {
+		return nil, err
+	}
+
+	cfg, err := imageConfig(original)
+	if err != nil {
+		return nil, err
+	}
+
+	var posters = []task.Poster{{
+		Path:   "poster.jpg",
+		Format: "jpg",
+		Width:  cfg.Width,
+		Height: cfg.Height,
+	}}
+
+	for _, width := range posterWidths {
+		if width >= cfg.Width {
+			continue
+		}
+
+		var height int
+		for _, format := range posterFormats {
+			var (
+				name = fmt.Sprintf("poster_%dw.%s", width, format)
+				dst  = filepath.Join(assetsDir, name)
+			)
+			if err := ffmpeg.PosterVariant(ctx, original, dst, width); err != nil {
+				return posters, err
+			}
+
+			// same scale filter for every format, jpeg goes first
+			if height == 0 {
+				variant, err := imageConfig(dst)
+				if err != nil {
+					return posters, err
+				}
+				height = variant.Height
+			}
+
+			posters = append(posters, task.Poster{
+				Path:   name,
+				Format: format,
+				Width:  width,
+				Height: height,
+			})
+		}
+	}
+
+	return posters, nil
+}
+
+func imageConfig(path string) (image.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	return cfg, err
+}
@@ -3,23 +3,26 @@ package assembler
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/timohahaa/transcoder/internal/composer/modules/asset"
+	"github.com/timohahaa/transcoder/internal/composer/modules/meta"
 	"github.com/timohahaa/transcoder/internal/composer/modules/task"
 	"github.com/timohahaa/transcoder/pkg/errors"
 	"github.com/timohahaa/transcoder/pkg/ffmpeg"
 )
 
-const fragmentSizeSeconds = 4
-
 func (a *Assembler) process(t task.Task) (task.Task, error) {
 	var (
 		lg          = a.l.WithFields(log.Fields{"task_id": t.ID})
 		ctx, cancel = context.WithCancel(context.Background())
 		taskDir     = filepath.Join(a.cfg.WorkDir, t.ID.String())
-		assetsDir   = filepath.Join(taskDir, "assets")
+		stitchedDir = filepath.Join(taskDir, "stitched")
+		assetsDir   = filepath.Join(taskDir, "assets") // everything here gets published
 		err         error
 	)
 	defer cancel()
@@ -58,7 +61,6 @@ func (a *Assembler) process(t task.Task) (task.Task, error) {
 		audios      map[string]string
 		poster      string
 	)
-	_ = poster
 	{
 		if videoChunks, err = a.findQualityChunks(taskDir); err != nil {
 			return t, errors.Assembler(err)
@@ -66,8 +68,10 @@ func (a *Assembler) process(t task.Task) (task.Task, error) {
 		if audios, err = a.findAudios(taskDir); err != nil {
 			return t, errors.Assembler(err)
 		}
+		// a task without a poster is still usable
 		if poster, err = a.findPoster(taskDir); err != nil {
-			return t, errors.Assembler(err)
+			lg.Warnf("find poster: %v", err)
+			t.Result.Warn("poster is missing")
 		}
 	}
 
@@ -77,7 +81,7 @@ func (a *Assembler) process(t task.Task) (task.Task, error) {
 		outFile, err := ffmpeg.Stitch(
 			ctx,
 			chunks,
-			assetsDir,
+			stitchedDir,
 			fmt.Sprintf("%v_stitched.mp4", quality),
 		)
 		if err != nil {
@@ -89,53 +93,181 @@ func (a *Assembler) process(t task.Task) (task.Task, error) {
 
 	progress(task.ProgressAfterStitch)
 
-	// fragment videos, audios
+	// validate stitched videos, audios
+	m, err := meta.Read(taskDir)
+	switch {
+	case os.IsNotExist(err):
+		lg.Warn("no split meta, skip post validation")
+	case err != nil:
+		return t, errors.Assembler(err)
+	default:
+		if err := postValidate(ctx, m, videoChunks, stitchedVideos, audios); err != nil {
+			return t, err
+		}
+	}
+
+	// subtitles are converted by the splitter, just publish them
+	subtitles, warnings, err := moveSubtitles(lg, m, taskDir, assetsDir)
+	if err != nil {
+		return t, errors.Assembler(err)
+	}
+	for _, warning := range warnings {
+		t.Result.Warn(warning)
+	}
+
+	// package videos, audios: fragmented files or init + media segments
 	var (
-		fragVideos = make(map[string]string, len(stitchedVideos))
-		fragAudios = make(map[string]string, len(audios))
+		packVideos = make(map[string]packaged, len(stitchedVideos))
+		packAudios = make(map[string]packaged, len(audios))
 	)
 
 	for quality, file := range stitchedVideos {
-		outFile, err := ffmpeg.Fragment(
-			ctx,
-			file,
-			assetsDir,
-			fmt.Sprintf("%v_frag.mp4", quality),
-			fragmentSizeSeconds,
-		)
+		out, err := pack(ctx, t.Settings, file, assetsDir, quality)
 		if err != nil {
 			return t, errors.FragmentSources(err)
 		}
 
-		fragVideos[quality] = outFile
+		packVideos[quality] = out
 	}
 	progress(task.ProgressAfterFragmentVideo)
 
 	for quality, file := range audios {
-		outFile, err := ffmpeg.Fragment(
-			ctx,
-			file,
-			assetsDir,
-			fmt.Sprintf("%v_frag.mp4", quality),
-			fragmentSizeSeconds,
-		)
+		out, err := pack(ctx, t.Settings, file, assetsDir, quality)
 		if err != nil {
 			return t, errors.FragmentSources(err)
 		}
 
-		fragAudios[quality] = outFile
+		packAudios[quality] = out
 	}
 	progress(task.ProgressAfterFragmentAudio)
 
-	//	@todo:
-	// maybe encrypt videos, audios if needed???
+	// encrypt videos, audios
+	var prot *protection
+	if t.Settings.Encrypt {
+		if prot, err = a.protection(ctx, t); err != nil {
+			return t, errors.EncryptSources(err)
+		}
+
+		for _, outputs := range []map[string]packaged{packVideos, packAudios} {
+			for _, out := range outputs {
+				if err := encrypt(ctx, out, prot.Key); err != nil {
+					return t, errors.EncryptSources(err)
+				}
+			}
+		}
+		progress(task.ProgressAfterEncrypt)
+	}
+
 	// generate manifests
-	// upload assets (where???)
+	var videoRenditions, audioRenditions []rendition
+	{
+		if videoRenditions, err = readRenditions(ctx, packVideos); err != nil {
+			return t, errors.GenerateManifests(err)
+		}
+		if audioRenditions, err = readRenditions(ctx, packAudios); err != nil {
+			return t, errors.GenerateManifests(err)
+		}
+		attachTracks(audioRenditions, m.Audios)
+
+		if _, err := writeHLS(assetsDir, videoRenditions, audioRenditions, subtitles, prot); err != nil {
+			return t, errors.GenerateManifests(err)
+		}
+		if _, err := writeDASH(
+			assetsDir,
+			videoRenditions,
+			audioRenditions,
+			subtitles,
+			prot,
+			float64(t.Settings.KeyframeTiming().Segment),
+		); err != nil {
+			return t, errors.GenerateManifests(err)
+		}
+	}
+	progress(task.ProgressAfterManifests)
+
+	// thumbnails are optional, a task is not failed because of them
+	if m.Thumbnails != nil {
+		var dstDir = filepath.Join(assetsDir, thumbnailsDir)
+		if _, err := writeThumbnails(lg, m, filepath.Join(taskDir, "thumbnails"), dstDir); err != nil {
+			lg.Warnf("thumbnails: %v", err)
+			t.Result.Warn("thumbnails: " + err.Error())
+			_ = os.RemoveAll(dstDir)
+		}
+	}
+
+	if poster != "" {
+		posters, err := writePosters(ctx, poster, assetsDir)
+		if err != nil {
+			lg.Warnf("posters: %v", err)
+			t.Result.Warn("poster variants: " + err.Error())
+		}
+		t.Result.Posters = posters
+	}
+
+	if t.Settings.Metrics() {
+		metrics, err := a.mod.task.GetMetrics(ctx, t.ID)
+		switch {
+		case err != nil:
+			lg.Warnf("quality metrics: %v", err)
+			t.Result.Warn("quality metrics: " + err.Error())
+		case len(metrics) < len(videoRenditions):
+			t.Result.Warn(fmt.Sprintf(
+				"quality metrics: %d of %d video renditions scored", len(metrics), len(videoRenditions),
+			))
+		}
+		t.Result.Metrics = metrics
+
+		if unscored := unscoredRenditions(metrics); t.Settings.QualityGate != nil && len(unscored) > 0 {
+			t.Result.Warn(fmt.Sprintf(
+				"quality gate is not applied to renditions without VMAF scores, encoders have no libvmaf: %s",
+				strings.Join(unscored, ", "),
+			))
+		}
+	}
+
+	// upload assets
+	var assets []asset.Asset
+	{
+		if assets, err = describeAssets(assetsDir, videoRenditions, audioRenditions); err != nil {
+			return t, errors.Assembler(err)
+		}
+
+		st, err := a.mod.asset.Storage(t)
+		if err != nil {
+			return t, errors.UploadAssets(err)
+		}
+		if err := a.upload(ctx, st, assetsDir); err != nil {
+			return t, errors.UploadAssets(err)
+		}
+		lg.Infof("assets uploaded to: %v", st.Location(""))
+
+		for i := range assets {
+			assets[i].Location = st.Location(assets[i].Path)
+		}
+	}
+	progress(task.ProgressAfterUpload)
 
 	// update db
+	if err := a.mod.asset.Save(ctx, t.ID, assets); err != nil {
+		return t, errors.DB(err)
+	}
+	if err := a.mod.task.UpdateResult(ctx, t.ID, t.Result); err != nil {
+		return t, errors.DB(err)
+	}
 	if err := a.mod.task.UpdateStatus(ctx, t.ID, task.StatusDone, nil); err != nil {
 		return t, errors.DB(err)
 	}
 
 	return t, nil
 }
+
+// renditions the quality gate couldn't judge
+func unscoredRenditions(metrics []task.RenditionMetrics) []string {
+	var res []string
+	for _, m := range metrics {
+		if m.VMAF == nil {
+			res = append(res, m.Rendition)
+		}
+	}
+	return res
+}
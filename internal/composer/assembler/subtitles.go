@@ -0,0 +1,93 @@
+package assembler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/timohahaa/transcoder/internal/composer/modules/meta"
+)
+
+const subtitlesDir = "subtitles"
+
+// WebVTT subtitle track, sidecar file in both HLS and DASH
+type subtitle struct {
+	meta.Subtitle
+	Path     string // in assets dir
+	Size     int64
+	Duration float64
+}
+
+func (s subtitle) name() string {
+	return fmt.Sprintf("subtitle_%d", s.Num)
+}
+
+// human readable track name for players
+func (s subtitle) label() string {
+	switch {
+	case s.Title != "":
+		return s.Title
+	case s.Language != "":
+		return s.Language
+	default:
+		return s.name()
+	}
+}
+
+// uri of the file relative to manifests
+func (s subtitle) uri() string {
+	return subtitlesDir + "/" + filepath.Base(s.Path)
+}
+
+// bits per second, manifests require non zero bandwidth
+func (s subtitle) bitrate() int64 {
+	if s.Duration <= 0 {
+		return 1
+	}
+	return max(1, int64(float64(s.Size*8)/s.Duration))
+}
+
+// moves subtitles converted by the splitter to assetsDir/subtitles,
+// missing ones are skipped with a warning
+func moveSubtitles(lg *log.Entry, m meta.Meta, taskDir, assetsDir string) ([]subtitle, []string, error) {
+	if len(m.Subtitles) == 0 {
+		return nil, nil, nil
+	}
+
+	var dstDir = filepath.Join(assetsDir, subtitlesDir)
+	if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		subtitles []subtitle
+		warnings  []string
+	)
+	for _, s := range m.Subtitles {
+		var (
+			src = filepath.Join(taskDir, s.File)
+			sub = subtitle{
+				Subtitle: s,
+				Path:     filepath.Join(dstDir, fmt.Sprintf("subtitle_%d.vtt", s.Num)),
+				Duration: m.Duration,
+			}
+		)
+
+		info, err := os.Stat(src)
+		if err != nil {
+			lg.Warnf("subtitle %v: %v", s.Num, err)
+			warnings = append(warnings, fmt.Sprintf("subtitle %v (%v) is missing", s.Num, sub.label()))
+			continue
+		}
+		sub.Size = info.Size()
+
+		if err := os.Rename(src, sub.Path); err != nil {
+			return nil, nil, err
+		}
+
+		subtitles = append(subtitles, sub)
+	}
+
+	return subtitles, warnings, nil
+}
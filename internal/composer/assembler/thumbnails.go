@@ -0,0 +1,167 @@
+package assembler
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/timohahaa/transcoder/internal/composer/modules/meta"
+)
+
+const (
+	thumbnailsDir     = "thumbnails"
+	thumbnailsVTT     = "thumbnails.vtt"
+	spriteNamePattern = "sprite_%03d.jpg"
+	spriteQuality     = 80
+)
+
+var errNoThumbnails = errors.New("no thumbnail strips")
+
+// implemented by every image type jpeg.Decode returns
+type subImager interface {
+	SubImage(image.Rectangle) image.Image
+}
+
+// writeThumbnails cuts per-chunk strips into single thumbnails,
+// packs them into sprite sheets and writes a WebVTT track pointing into them.
+// Missing or broken strips leave blank tiles, so cue timings stay intact.
+func writeThumbnails(lg *log.Entry, m meta.Meta, stripsDir, dstDir string) (string, error) {
+	var tiles []image.Image // one per thumbnail in playback order, nil if missing
+	for _, chunk := range m.Chunks {
+		if chunk.Thumbnails == nil {
+			continue
+		}
+
+		var (
+			count      = int(chunk.Thumbnails.Count)
+			path       = filepath.Join(stripsDir, fmt.Sprintf("strip_%03d.jpg", chunk.Num))
+			strip, err = readJPEG(path)
+		)
+		if err == nil {
+			if _, ok := strip.(subImager); !ok {
+				err = fmt.Errorf("unsupported image type %T", strip)
+			}
+		}
+		if err != nil {
+			lg.Warnf("thumbnails strip %v: %v", chunk.Num, err)
+			tiles = append(tiles, make([]image.Image, count)...)
+			continue
+		}
+
+		var (
+			bounds = strip.Bounds()
+			width  = bounds.Dx() / count
+		)
+		for i := range count {
+			var rect = image.Rect(
+				bounds.Min.X+i*width, bounds.Min.Y,
+				bounds.Min.X+(i+1)*width, bounds.Max.Y,
+			)
+			tiles = append(tiles, strip.(subImager).SubImage(rect))
+		}
+	}
+
+	// every strip is scaled to the same width, take the cell size from any of them
+	var cell image.Rectangle
+	for _, tile := range tiles {
+		if tile != nil {
+			cell = image.Rect(0, 0, tile.Bounds().Dx(), tile.Bounds().Dy())
+			break
+		}
+	}
+	if cell.Empty() {
+		return "", errNoThumbnails
+	}
+
+	if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	var (
+		th      = m.Thumbnails
+		perPage = th.Columns * th.Rows
+		vtt     strings.Builder
+	)
+	vtt.WriteString("WEBVTT\n")
+
+	for page := 0; page*perPage < len(tiles); page++ {
+		var (
+			pageTiles = tiles[page*perPage : min((page+1)*perPage, len(tiles))]
+			columns   = min(th.Columns, len(pageTiles))
+			rows      = (len(pageTiles) + th.Columns - 1) / th.Columns
+			sprite    = image.NewRGBA(image.Rect(0, 0, columns*cell.Dx(), rows*cell.Dy()))
+			name      = fmt.Sprintf(spriteNamePattern, page)
+		)
+
+		for i, tile := range pageTiles {
+			var (
+				pos = image.Pt(i%th.Columns*cell.Dx(), i/th.Columns*cell.Dy())
+				k   = page*perPage + i
+			)
+			if tile != nil {
+				draw.Draw(sprite, cell.Add(pos), tile, tile.Bounds().Min, draw.Src)
+			}
+
+			var (
+				start = float64(k) * th.Interval
+				end   = min(float64(k+1)*th.Interval, m.Duration)
+			)
+			if end <= start {
+				continue
+			}
+			fmt.Fprintf(&vtt, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n",
+				vttTimestamp(start),
+				vttTimestamp(end),
+				name,
+				pos.X, pos.Y, cell.Dx(), cell.Dy(),
+			)
+		}
+
+		if err := writeJPEG(filepath.Join(dstDir, name), sprite); err != nil {
+			return "", err
+		}
+	}
+
+	var vttPath = filepath.Join(dstDir, thumbnailsVTT)
+	return vttPath, os.WriteFile(vttPath, []byte(vtt.String()), 0o644)
+}
+
+func vttTimestamp(seconds float64) string {
+	var ms = int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d",
+		ms/3_600_000,
+		ms/60_000%60,
+		ms/1000%60,
+		ms%1000,
+	)
+}
+
+func readJPEG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return jpeg.Decode(f)
+}
+
+func writeJPEG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := jpeg.Encode(f, img, &jpeg.Options{Quality: spriteQuality}); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,66 @@
+package assembler
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+
+	"github.com/timohahaa/transcoder/pkg/storage"
+	"golang.org/x/sync/errgroup"
+)
+
+const uploadWorkers = 8
+
+// uploads everything from assetsDir keeping relative paths,
+// manifests go last, so they never reference missing media
+func (a *Assembler) upload(ctx context.Context, st storage.Storage, assetsDir string) error {
+	var media, manifests []string
+
+	err := filepath.WalkDir(assetsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		key, err := filepath.Rel(assetsDir, path)
+		if err != nil {
+			return err
+		}
+		key = filepath.ToSlash(key)
+
+		if isManifest(key) {
+			manifests = append(manifests, key)
+		} else {
+			media = append(media, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, keys := range [][]string{media, manifests} {
+		var g, gCtx = errgroup.WithContext(ctx)
+		g.SetLimit(uploadWorkers)
+
+		for _, key := range keys {
+			g.Go(func() error {
+				if err := st.Put(gCtx, key, filepath.Join(assetsDir, filepath.FromSlash(key))); err != nil {
+					return fmt.Errorf("upload %v: %w", st.Location(key), err)
+				}
+				return nil
+			})
+		}
+
+		if err := g.Wait(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isManifest(key string) bool {
+	return slices.Contains([]string{".m3u8", ".mpd"}, filepath.Ext(key))
+}
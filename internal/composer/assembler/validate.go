@@ -0,0 +1,269 @@
+package assembler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/timohahaa/transcoder/internal/composer/modules/meta"
+	"github.com/timohahaa/transcoder/pkg/errors"
+	"github.com/timohahaa/transcoder/pkg/ffmpeg"
+	"github.com/timohahaa/transcoder/pkg/ffprobe"
+	pb "github.com/timohahaa/transcoder/proto/composer"
+)
+
+const (
+	// seconds, same as splitter pre validation
+	durationTolerance = 0.5
+	// average bitrate can't be much higher than the preset maxrate
+	bitrateTolerance = 1.5
+	// default keyframes tolerance if frame rate is unknown, seconds
+	keyframeTolerance = 0.02
+)
+
+// validates stitched renditions and encoded audios against what the splitter asked for,
+// every failed check is reported, not only the first one
+func postValidate(
+	ctx context.Context,
+	m meta.Meta,
+	videoChunks map[string][]string,
+	stitched map[string]string,
+	audios map[string]string,
+) error {
+	var issues errors.Issues
+
+	issues = append(issues, validateChunkSets(m, videoChunks)...)
+
+	var (
+		infos     = make(map[string]*ffprobe.Info, len(stitched))
+		qualities = make([]string, 0, len(stitched))
+	)
+	for quality, path := range stitched {
+		info, err := ffprobe.GetInfo(ctx, path)
+		if err != nil {
+			return errors.Assembler(err)
+		}
+		infos[quality] = info
+		qualities = append(qualities, quality)
+	}
+	// lowest quality first, H.264 before other codecs of the same quality
+	slices.SortFunc(qualities, compareRenditions)
+
+	var presets = presetsByQuality(m)
+	for _, quality := range qualities {
+		var info = infos[quality]
+
+		if dur := info.GetDuration(); math.Abs(dur-m.Duration) > durationTolerance {
+			issues = append(issues, fmt.Sprintf(
+				"%v: duration %.3f, source %.3f", quality, dur, m.Duration,
+			))
+		}
+
+		if p, ok := presets[quality]; ok {
+			issues = append(issues, validatePreset(quality, info, p, expectedBitrate(m, quality))...)
+		}
+	}
+
+	kfIssues, err := validateKeyframes(ctx, qualities, stitched, infos)
+	if err != nil {
+		return errors.Assembler(err)
+	}
+	issues = append(issues, kfIssues...)
+
+	var expected int
+	for _, t := range m.Audios {
+		for _, name := range audioNames(t) {
+			if _, ok := audios[name]; !ok {
+				issues = append(issues, fmt.Sprintf("%v: not encoded", name))
+			}
+			expected++
+		}
+	}
+	if len(audios) != expected {
+		issues = append(issues, fmt.Sprintf(
+			"got %d audio renditions, expected %d", len(audios), expected,
+		))
+	}
+	for name, path := range audios {
+		info, err := ffprobe.GetInfo(ctx, path)
+		if err != nil {
+			return errors.Assembler(err)
+		}
+
+		if dur := info.GetDuration(); math.Abs(dur-m.Duration) > durationTolerance {
+			issues = append(issues, fmt.Sprintf(
+				"%v: duration %.3f, source %.3f", name, dur, m.Duration,
+			))
+		}
+	}
+
+	if len(issues) > 0 {
+		slices.Sort(issues)
+		return errors.PostValidation(issues)
+	}
+	return nil
+}
+
+// every quality must have every chunk number exactly once
+func validateChunkSets(m meta.Meta, videoChunks map[string][]string) []string {
+	var issues []string
+
+	for quality := range presetsByQuality(m) {
+		if _, ok := videoChunks[quality]; !ok {
+			issues = append(issues, fmt.Sprintf("%v: no chunks encoded", quality))
+		}
+	}
+
+	for quality, chunks := range videoChunks {
+		var got = make(map[int]int, len(chunks))
+		for _, c := range chunks {
+			num, err := ffmpeg.ChunkNum(filepath.Base(c))
+			if err != nil {
+				issues = append(issues, fmt.Sprintf("%v: %v", quality, err))
+				continue
+			}
+			got[num]++
+		}
+
+		var missing []string
+		for _, c := range m.Chunks {
+			if got[c.Num] == 0 {
+				missing = append(missing, strconv.Itoa(c.Num))
+			}
+			delete(got, c.Num)
+		}
+		if len(missing) > 0 {
+			issues = append(issues, fmt.Sprintf(
+				"%v: missing chunks %v", quality, strings.Join(missing, ","),
+			))
+		}
+
+		if len(got) > 0 {
+			var unexpected []int
+			for num := range got {
+				unexpected = append(unexpected, num)
+			}
+			slices.Sort(unexpected)
+			issues = append(issues, fmt.Sprintf(
+				"%v: unexpected chunks %v", quality, unexpected,
+			))
+		}
+	}
+
+	return issues
+}
+
+func validatePreset(quality string, info *ffprobe.Info, p *pb.Preset, expBitrate int64) []string {
+	var (
+		issues []string
+		stream = info.GetHighestVideo()
+		w, h   = int(p.Width), int(p.Height)
+	)
+
+	// encoder swaps preset dimensions for vertical videos
+	if p.IsVertical {
+		w, h = h, w
+	}
+
+	if stream.Width != w || stream.Height != h {
+		issues = append(issues, fmt.Sprintf(
+			"%v: resolution %dx%d, preset %dx%d", quality, stream.Width, stream.Height, w, h,
+		))
+	}
+
+	if bitrate := info.Format.BitRate; expBitrate > 0 && float64(bitrate) > float64(expBitrate)*bitrateTolerance {
+		issues = append(issues, fmt.Sprintf(
+			"%v: bitrate %d, preset %d", quality, bitrate, expBitrate,
+		))
+	}
+
+	return issues
+}
+
+// renditions must have keyframes at the same timestamps,
+// otherwise players can't switch between them on segment boundaries
+func validateKeyframes(
+	ctx context.Context,
+	qualities []string,
+	stitched map[string]string,
+	infos map[string]*ffprobe.Info,
+) ([]string, error) {
+	if len(qualities) < 2 {
+		return nil, nil
+	}
+
+	var (
+		issues    []string
+		ref       = qualities[0]
+		tolerance = keyframeTolerance
+	)
+
+	if fps := frameRate(infos[ref].GetHighestVideo()); fps > 0 {
+		tolerance = 0.5 / fps
+	}
+
+	refKeyframes, err := ffprobe.Keyframes(ctx, stitched[ref])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, quality := range qualities[1:] {
+		keyframes, err := ffprobe.Keyframes(ctx, stitched[quality])
+		if err != nil {
+			return nil, err
+		}
+
+		if len(keyframes) != len(refKeyframes) {
+			issues = append(issues, fmt.Sprintf(
+				"%v: %d keyframes, %v has %d", quality, len(keyframes), ref, len(refKeyframes),
+			))
+			continue
+		}
+
+		for i := range keyframes {
+			if math.Abs(keyframes[i]-refKeyframes[i]) > tolerance {
+				issues = append(issues, fmt.Sprintf(
+					"%v: keyframe #%d at %.3f, %v has it at %.3f",
+					quality, i, keyframes[i], ref, refKeyframes[i],
+				))
+				break
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// presets of the same rendition differ only in bitrate from chunk to chunk
+func presetsByQuality(m meta.Meta) map[string]*pb.Preset {
+	var res = map[string]*pb.Preset{}
+	for _, c := range m.Chunks {
+		for _, p := range c.Presets {
+			if _, ok := res[p.Name()]; !ok {
+				res[p.Name()] = p
+			}
+		}
+	}
+	return res
+}
+
+// chunk duration weighted average of preset maxrates
+func expectedBitrate(m meta.Meta, quality string) int64 {
+	var bits, dur float64
+	for _, c := range m.Chunks {
+		for _, p := range c.Presets {
+			if p.Name() == quality && p.MaxBitRate > 0 {
+				bits += float64(p.MaxBitRate) * c.Duration
+				dur += c.Duration
+			}
+		}
+	}
+	if dur == 0 {
+		return 0
+	}
+	return int64(bits / dur)
+}
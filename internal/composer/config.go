@@ -1,17 +1,23 @@
 package composer
 
 import (
+	"net"
 	"os"
 	"path/filepath"
 )
 
 type (
 	Config struct {
-		PostgresDSN string `arg:"required,-,--,env:POSTGRES_DSN"`
-		HttpAddr    string `arg:"required,-,--,env:HTTP_ADDR"`
-		GrpcAddr    string `arg:"required,-,--,env:GRPC_ADDR"`
-		WorkDir     string `arg:"-,--,env:WORK_DIR"`
+		PostgresDSN  string `arg:"required,-,--,env:POSTGRES_DSN"`
+		HttpAddr     string `arg:"required,-,--,env:HTTP_ADDR"`
+		GrpcAddr     string `arg:"required,-,--,env:GRPC_ADDR"`
+		WorkDir      string `arg:"-,--,env:WORK_DIR"`
+		KeyServerURL string `arg:"-,--,env:KEY_SERVER_URL"` // public url of key server, written into manifests
+		OutputDir    string `arg:"-,--,env:OUTPUT_DIR"`     // destination of tasks without one
+		// serves /v1/keys without auth, dev only, production players get keys from DRM vendor
+		KeyServerEnabled bool `arg:"-,--,env:KEY_SERVER_ENABLED"`
 		Redis
+		S3
 		Splitter
 		Assembler
 	}
@@ -20,6 +26,14 @@ type (
 		Username string   `arg:"required,-,--,env:REDIS_USERNAME"`
 		Password string   `arg:"required,-,--,env:REDIS_PASSWORD"`
 	}
+	// S3-compatible storage for s3 destinations
+	S3 struct {
+		Endpoint  string `arg:"-,--,env:S3_ENDPOINT"`
+		Region    string `arg:"-,--,env:S3_REGION"`
+		AccessKey string `arg:"-,--,env:S3_ACCESS_KEY"`
+		SecretKey string `arg:"-,--,env:S3_SECRET_KEY"`
+		UseSSL    bool   `arg:"-,--,env:S3_USE_SSL"`
+	}
 	Splitter struct {
 		Workers  int `arg:"-,--,env:SPLITTER_WORKERS"`
 		Watchers int `arg:"-,--,env:SPLITTER_WATCHERS"`
@@ -46,10 +60,17 @@ func (c *Config) setDefaults() {
 	if c.WorkDir == "" {
 		c.WorkDir = filepath.Join(os.TempDir(), "composer")
 	}
+	if c.OutputDir == "" {
+		c.OutputDir = filepath.Join(os.TempDir(), "composer-output")
+	}
 	if c.HttpAddr == "" {
 		c.HttpAddr = ":8080"
 	}
 	if c.GrpcAddr == "" {
 		c.GrpcAddr = ":9090"
 	}
+	if c.KeyServerURL == "" {
+		_, port, _ := net.SplitHostPort(c.HttpAddr)
+		c.KeyServerURL = "http://localhost:" + port + "/v1/keys"
+	}
 }
@@ -0,0 +1,74 @@
+package composer
+
+import (
+	"slices"
+
+	pb "github.com/timohahaa/transcoder/proto/composer"
+	"google.golang.org/protobuf/proto"
+)
+
+// every retry of a rendition failing the quality gate
+const (
+	retryRateMultiplier = 1.5
+	retryCRFStep        = 3
+	minRetryCRF         = 10
+)
+
+// renditions of the chunk scoring below the gate floor,
+// ones without VMAF scores are never failed
+func failedRenditions(task *pb.Task, metrics []*pb.RenditionMetrics) []string {
+	var (
+		gate = task.GetVideo().GetGate()
+		res  []string
+	)
+	if gate == nil {
+		return nil
+	}
+
+	for _, m := range metrics {
+		if m.VMAF != nil && m.VMAF.Mean < float64(gate.MinVMAF) {
+			res = append(res, m.Rendition)
+		}
+	}
+	return res
+}
+
+// renditions the gate can't judge, encoders without libvmaf report PSNR/SSIM only
+func unscoredRenditions(task *pb.Task, metrics []*pb.RenditionMetrics) []string {
+	if task.GetVideo().GetGate() == nil {
+		return nil
+	}
+
+	var res []string
+	for _, m := range metrics {
+		if m.VMAF == nil {
+			res = append(res, m.Rendition)
+		}
+	}
+	return res
+}
+
+// retrySubtask re-issues the chunk with failed renditions only,
+// their maxrate is raised and CRF lowered on every retry
+func retrySubtask(task *pb.Task, failed []string) *pb.Task {
+	var gate = task.GetVideo().GetGate()
+	if len(failed) == 0 || gate.GetRetry() >= gate.GetMaxRetries() {
+		return nil
+	}
+
+	var retry = proto.Clone(task).(*pb.Task)
+	retry.Video.CreatePoster = false
+	retry.Video.Thumbnails = nil
+	retry.Video.Gate.Retry++
+	retry.Video.Gate.Renditions = failed
+
+	for _, p := range retry.Video.Presets {
+		if !slices.Contains(failed, p.Name()) {
+			continue
+		}
+		p.MaxBitRate = int64(float64(p.MaxBitRate) * retryRateMultiplier)
+		p.Bufsize = p.MaxBitRate * 2
+		p.CRF = max(p.CRF-retryCRFStep, minRetryCRF)
+	}
+	return retry
+}
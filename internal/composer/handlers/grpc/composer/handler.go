@@ -117,7 +117,31 @@ func (h *Handler) FinishTask(ctx context.Context, req *pb.FinishTaskRequest) (*e
 		return &emptypb.Empty{}, nil
 	}
 
-	currSubtaskCount, err := h.mod.queue.FinishSubtask(ctx, taskID)
+	// before the subtask is counted, so all scores are there once the task is assembled
+	if len(req.Metrics) > 0 {
+		if err := h.mod.task.SaveChunkMetrics(ctx, taskID, req.Task.Part, req.Metrics); err != nil {
+			lg.Warnf("save chunk metrics: %v", err)
+		}
+	}
+
+	if unscored := unscoredRenditions(req.Task, req.Metrics); len(unscored) > 0 {
+		lg.Warnf("part %d has no VMAF scores, quality gate is not applied: %v", req.Task.Part, unscored)
+	}
+
+	if failed := failedRenditions(req.Task, req.Metrics); len(failed) > 0 {
+		var retry = retrySubtask(req.Task, failed)
+		if retry == nil {
+			lg.Warnf("part %d accepted below quality gate: %v", req.Task.Part, failed)
+		} else if err := h.requeue(ctx, taskID, retry); err != nil {
+			lg.Errorf("requeue part %d, accepted below quality gate: %v", req.Task.Part, err)
+		} else {
+			lg.Infof("part %d re-queued (retry %d): %v", req.Task.Part, retry.Video.Gate.Retry, failed)
+			// counted once the retry is finished
+			return &emptypb.Empty{}, nil
+		}
+	}
+
+	currSubtaskCount, added, err := h.mod.queue.FinishSubtask(ctx, taskID, req.Task.Part)
 	if err != nil {
 		lg.Errorf("finish subtask: %v", err)
 		return nil, status.Error(codes.Internal, err.Error())
@@ -134,7 +158,7 @@ func (h *Handler) FinishTask(ctx context.Context, req *pb.FinishTaskRequest) (*e
 			lg.Errorf("update task status: %v", err)
 			return nil, status.Error(codes.Internal, err.Error())
 		}
-	case currSubtaskCount == int64(req.Task.PartsTotal):
+	case added && currSubtaskCount == int64(req.Task.PartsTotal):
 		if err := h.mod.task.UpdateStatus(
 			ctx,
 			taskID,
@@ -152,3 +176,12 @@ func (h *Handler) FinishTask(ctx context.Context, req *pb.FinishTaskRequest) (*e
 
 	return &emptypb.Empty{}, nil
 }
+
+// re-queued to the routing of the task, the chunk is replaced once the retry is pushed
+func (h *Handler) requeue(ctx context.Context, taskID uuid.UUID, retry *pb.Task) error {
+	t, err := h.mod.task.Get(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	return h.mod.queue.AddSubtask(ctx, t.Routing, retry)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 
 	"github.com/google/uuid"
@@ -49,7 +50,7 @@ func (h *handlers) getFile(w http.ResponseWriter, r *http.Request) {
 		l.Errorf("copy file: %v", err)
 	}
 	if n != s.Size() {
-		l.Error("resp body size mismatch: filesize = %v, body = %v", s.Size(), n)
+		l.Errorf("resp body size mismatch: filesize = %v, body = %v", s.Size(), n)
 	}
 }
 
@@ -97,6 +98,75 @@ func (h *handlers) pushChunk(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// re-encoded chunk replaces the previous one only once it is complete
+	var tmpPath = filepath.Join(filepath.Dir(dstPath), "."+filepath.Base(dstPath)+".part")
+
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		l.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	removeTmp := true
+	defer func() {
+		f.Close()
+		if removeTmp {
+			if err := os.Remove(tmpPath); err != nil {
+				l.Errorf("remove source due to error: %v", err)
+			}
+		}
+	}()
+
+	n, err := io.Copy(f, r.Body)
+	if err != nil {
+		l.Errorf("copy file: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if n != r.ContentLength {
+		l.Errorf("content length header didn't match file size: %v vs %v", r.ContentLength, n)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	f.Sync()
+	if err := os.Rename(tmpPath, dstPath); err != nil {
+		l.Errorf("replace chunk: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	removeTmp = false
+}
+
+func (h *handlers) pushPoster(w http.ResponseWriter, r *http.Request) {
+	var (
+		q           = r.URL.Query()
+		taskID, err = uuid.Parse(q.Get("task_id"))
+	)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	l := log.WithFields(log.Fields{
+		"mod":     "http",
+		"task_id": taskID,
+	})
+
+	var dstPath = filepath.Join(
+		h.workDir,
+		taskID.String(),
+		"poster",
+		"poster.jpg",
+	)
+	if err := os.MkdirAll(filepath.Dir(dstPath), os.ModePerm); err != nil {
+		l.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	f, err := os.Create(dstPath)
 	if err != nil {
 		l.Error(err)
@@ -131,26 +201,39 @@ func (h *handlers) pushChunk(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *handlers) pushPoster(w http.ResponseWriter, r *http.Request) {
+func (h *handlers) pushThumbnails(w http.ResponseWriter, r *http.Request) {
 	var (
 		q           = r.URL.Query()
 		taskID, err = uuid.Parse(q.Get("task_id"))
+		chunkNum    int
 	)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
+	if chunkNum, err = strconv.Atoi(q.Get("chunk_num")); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if chunkNum < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
 	l := log.WithFields(log.Fields{
 		"mod":     "http",
 		"task_id": taskID,
+		"chunk":   chunkNum,
+		"quality": "thumbnails",
 	})
 
 	var dstPath = filepath.Join(
 		h.workDir,
 		taskID.String(),
-		"poster",
-		"poster.jpg",
+		"thumbnails",
+		fmt.Sprintf("strip_%03d.jpg", chunkNum),
 	)
 	if err := os.MkdirAll(filepath.Dir(dstPath), os.ModePerm); err != nil {
 		l.Error(err)
@@ -192,6 +275,9 @@ func (h *handlers) pushPoster(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// rendition names become part of file names
+var renditionRe = regexp.MustCompile(`^[a-z0-9_-]*$`)
+
 func (h *handlers) getAudio(w http.ResponseWriter, r *http.Request) { h.getFile(w, r) }
 
 func (h *handlers) pushAudio(w http.ResponseWriter, r *http.Request) {
@@ -199,6 +285,7 @@ func (h *handlers) pushAudio(w http.ResponseWriter, r *http.Request) {
 		q           = r.URL.Query()
 		taskID, err = uuid.Parse(q.Get("task_id"))
 		trackNum    int
+		rendition   = q.Get("rendition") // empty for a single rendition track
 	)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -210,11 +297,16 @@ func (h *handlers) pushAudio(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if trackNum < 0 {
+	if trackNum < 0 || !renditionRe.MatchString(rendition) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
+	var fileName = fmt.Sprintf("audio_%d.mp4", trackNum)
+	if rendition != "" {
+		fileName = fmt.Sprintf("audio_%d_%s.mp4", trackNum, rendition)
+	}
+
 	l := log.WithFields(log.Fields{
 		"mod":     "http",
 		"task_id": taskID,
@@ -226,7 +318,7 @@ func (h *handlers) pushAudio(w http.ResponseWriter, r *http.Request) {
 		h.workDir,
 		taskID.String(),
 		"audios",
-		fmt.Sprintf("audio_%d.mp4", trackNum),
+		fileName,
 	)
 	if err := os.MkdirAll(filepath.Dir(dstPath), os.ModePerm); err != nil {
 		l.Error(err)
@@ -19,6 +19,7 @@ func New(workDir string) *chi.Mux {
 	mux.Get("/audio", h.getAudio)
 	mux.Post("/audio", h.pushAudio)
 	mux.Post("/poster", h.pushPoster)
+	mux.Post("/thumbnails", h.pushThumbnails)
 
 	return mux
 }
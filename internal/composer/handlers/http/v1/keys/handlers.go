@@ -0,0 +1,121 @@
+package keys
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	stdErrors "errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/timohahaa/transcoder/internal/utils/render"
+)
+
+// @Summary	Get content key
+// @Description	Raw 16-byte content key, used by HLS players (KEYFORMAT="identity"). Dev only, served with KEY_SERVER_ENABLED
+// @Tags		Keys
+// @Produce	octet-stream
+// @Param		key_id	path		string				true	"Key ID"
+// @Success	200		{file}		binary				"Content key"
+// @Failure	default	{object}	render.HTTPError	"Error"
+// @Router		/v1/keys/{key_id} [get]
+func (h *handlers) get(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx        = r.Context()
+		keyID, err = uuid.Parse(chi.URLParam(r, "key_id"))
+	)
+	if err != nil {
+		render.Error(w, err)
+		return
+	}
+
+	key, err := h.mod.drm.GetByKeyID(ctx, keyID)
+	if err != nil {
+		render.Error(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(key.Key)
+}
+
+// see https://www.w3.org/TR/encrypted-media/#clear-key-license-format
+type (
+	clearKeyRequest struct {
+		KIDs []string `json:"kids"`
+		Type string   `json:"type"`
+	}
+	clearKeyResponse struct {
+		Keys []clearKeyJWK `json:"keys"`
+		Type string        `json:"type"`
+	}
+	clearKeyJWK struct {
+		KTY string `json:"kty"`
+		KID string `json:"kid"`
+		K   string `json:"k"`
+	}
+)
+
+// @Summary	ClearKey license
+// @Description	EME ClearKey license exchange, used by DASH players. Response is not wrapped into data container. Dev only, served with KEY_SERVER_ENABLED
+// @Tags		Keys
+// @Param		LicenseRequest	body		clearKeyRequest		true	"License request"
+// @Success	200				{object}	clearKeyResponse	"License"
+// @Failure	default			{object}	render.HTTPError	"Error"
+// @Router		/v1/keys/clearkey [post]
+func (h *handlers) clearKey(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		req  clearKeyRequest
+		resp = clearKeyResponse{Keys: []clearKeyJWK{}, Type: "temporary"}
+		b64  = base64.RawURLEncoding
+	)
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		render.Error(w, err)
+		return
+	}
+	if req.Type != "" {
+		resp.Type = req.Type
+	}
+
+	for _, kid := range req.KIDs {
+		raw, err := b64.DecodeString(kid)
+		if err != nil {
+			render.Error(w, &render.HTTPError{
+				Status:  http.StatusBadRequest,
+				Message: "invalid kid",
+				Detail:  err.Error(),
+			})
+			return
+		}
+
+		keyID, err := uuid.FromBytes(raw)
+		if err != nil {
+			render.Error(w, err)
+			return
+		}
+
+		key, err := h.mod.drm.GetByKeyID(ctx, keyID)
+		if err != nil {
+			// player may ask for keys of other systems, skip unknown ones
+			if stdErrors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			render.Error(w, err)
+			return
+		}
+
+		resp.Keys = append(resp.Keys, clearKeyJWK{
+			KTY: "oct",
+			KID: kid,
+			K:   b64.EncodeToString(key.Key),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(resp)
+}
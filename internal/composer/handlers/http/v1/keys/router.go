@@ -0,0 +1,35 @@
+package keys
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/timohahaa/transcoder/internal/composer/modules/drm"
+)
+
+type (
+	handlers struct {
+		mod mod
+	}
+	mod struct {
+		drm *drm.Module
+	}
+)
+
+// local key delivery, lets encrypted outputs be played
+// without external DRM vendor, keys are not protected, so it is dev only
+// and mounted with KEY_SERVER_ENABLED
+func New(conn *pgxpool.Pool) *chi.Mux {
+	var (
+		mux = chi.NewMux()
+		h   = &handlers{
+			mod: mod{
+				drm: drm.New(conn),
+			},
+		}
+	)
+
+	mux.Post("/clearkey", h.clearKey)
+	mux.Get("/{key_id}", h.get)
+
+	return mux
+}
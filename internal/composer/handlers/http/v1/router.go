@@ -7,18 +7,27 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 	_ "github.com/timohahaa/transcoder/docs"
 	"github.com/timohahaa/transcoder/internal/composer/handlers/http/v1/files"
+	"github.com/timohahaa/transcoder/internal/composer/handlers/http/v1/keys"
 	"github.com/timohahaa/transcoder/internal/composer/handlers/http/v1/tasks"
+	"github.com/timohahaa/transcoder/internal/composer/modules/asset"
 )
 
 func New(
 	conn *pgxpool.Pool,
 	redis redis.UniversalClient,
 	workDir string,
-) *chi.Mux {
+	assetCfg asset.Config,
+	keyServer bool,
+) (*chi.Mux, error) {
 	var (
 		mux = chi.NewMux()
 	)
 
+	tasksMux, err := tasks.New(conn, redis, assetCfg)
+	if err != nil {
+		return nil, err
+	}
+
 	mux.Mount("/swagger", httpSwagger.Handler(
 		httpSwagger.DefaultModelsExpandDepth(httpSwagger.HideModel),
 		httpSwagger.UIConfig(map[string]string{
@@ -27,7 +36,11 @@ func New(
 		}),
 	))
 	mux.Mount("/files", files.New(workDir))
-	mux.Mount("/tasks", tasks.New(conn, redis))
+	mux.Mount("/tasks", tasksMux)
+	if keyServer {
+		// content keys are served to anyone, dev only
+		mux.Mount("/keys", keys.New(conn))
+	}
 
-	return mux
+	return mux, nil
 }
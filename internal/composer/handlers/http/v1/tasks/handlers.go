@@ -2,12 +2,16 @@ package tasks
 
 import (
 	"encoding/json"
+	"errors"
+	"io/fs"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/timohahaa/transcoder/internal/composer/modules/asset"
 	"github.com/timohahaa/transcoder/internal/composer/modules/task"
 	"github.com/timohahaa/transcoder/internal/utils/render"
+	"github.com/timohahaa/transcoder/pkg/storage"
 	"github.com/timohahaa/transcoder/pkg/validate"
 )
 
@@ -153,3 +157,99 @@ func (h *handlers) progress(w http.ResponseWriter, r *http.Request) {
 
 	render.JSON(w, taskProgress{Progress: prog})
 }
+
+// @Summary	List task assets
+// @Tags		Tasks
+// @Param		task_id	path		string				true	"Task ID"
+// @Success	200		{array}		asset.Asset			"Published files"
+// @Failure	default	{object}	render.HTTPError	"Error"
+// @Router		/v1/tasks/{task_id}/assets [get]
+func (h *handlers) assets(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx     = r.Context()
+		id, err = uuid.Parse(chi.URLParam(r, "task_id"))
+	)
+	if err != nil {
+		render.Error(w, err)
+		return
+	}
+
+	// not found for unknown tasks, empty list for unfinished ones
+	if _, err := h.mod.task.Get(ctx, id); err != nil {
+		render.Error(w, err)
+		return
+	}
+
+	var assets []asset.Asset
+	if assets, err = h.mod.asset.List(ctx, id); err != nil {
+		render.Error(w, err)
+		return
+	}
+
+	render.JSON(w, assets)
+}
+
+// @Summary	Download task asset
+// @Description	Supports Range requests. Assets published over HTTP are redirected to.
+// @Tags		Tasks
+// @Produce	octet-stream
+// @Param		task_id	path		string				true	"Task ID"
+// @Param		path	path		string				true	"Asset path"
+// @Success	200		{file}		file				"Asset"
+// @Success	206		{file}		file				"Asset range"
+// @Success	302		{object}	nil					"Asset location"
+// @Failure	default	{object}	render.HTTPError	"Error"
+// @Router		/v1/tasks/{task_id}/assets/{path} [get]
+func (h *handlers) download(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx     = r.Context()
+		id, err = uuid.Parse(chi.URLParam(r, "task_id"))
+		path    = chi.URLParam(r, "*")
+	)
+	if err != nil {
+		render.Error(w, err)
+		return
+	}
+
+	a, err := h.mod.asset.Get(ctx, id, path)
+	if err != nil {
+		render.Error(w, err)
+		return
+	}
+
+	t, err := h.mod.task.Get(ctx, id)
+	if err != nil {
+		render.Error(w, err)
+		return
+	}
+
+	st, err := h.mod.asset.Storage(t)
+	if err != nil {
+		render.Error(w, err)
+		return
+	}
+
+	opener, ok := st.(storage.Opener)
+	if !ok {
+		http.Redirect(w, r, a.Location, http.StatusFound)
+		return
+	}
+
+	f, err := opener.Open(ctx, a.Path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = &render.HTTPError{
+				Status:  http.StatusNotFound,
+				Message: "asset is missing in the destination",
+				Detail:  a.Location,
+			}
+		}
+		render.Error(w, err)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", storage.ContentType(a.Path))
+	w.Header().Set("ETag", `"`+a.SHA256+`"`)
+	http.ServeContent(w, r, a.Path, a.CreatedAt, f)
+}
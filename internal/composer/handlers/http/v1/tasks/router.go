@@ -4,6 +4,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
+	"github.com/timohahaa/transcoder/internal/composer/modules/asset"
 	"github.com/timohahaa/transcoder/internal/composer/modules/queue"
 	"github.com/timohahaa/transcoder/internal/composer/modules/task"
 )
@@ -15,10 +16,11 @@ type (
 	mod struct {
 		task  *task.Module
 		queue *queue.Module
+		asset *asset.Module
 	}
 )
 
-func New(conn *pgxpool.Pool, redis redis.UniversalClient) *chi.Mux {
+func New(conn *pgxpool.Pool, redis redis.UniversalClient, assetCfg asset.Config) (*chi.Mux, error) {
 	var (
 		mux = chi.NewMux()
 		h   = &handlers{
@@ -27,15 +29,22 @@ func New(conn *pgxpool.Pool, redis redis.UniversalClient) *chi.Mux {
 				queue: queue.New(conn, redis),
 			},
 		}
+		err error
 	)
 
+	if h.mod.asset, err = asset.New(conn, assetCfg); err != nil {
+		return nil, err
+	}
+
 	mux.Post("/", h.create)
 	mux.Route("/{task_id}", func(mux chi.Router) {
 		mux.Get("/", h.get)
 		mux.Delete("/", h.delete)
 		mux.Post("/cancel", h.cancel)
 		mux.Get("/progress", h.progress)
+		mux.Get("/assets", h.assets)
+		mux.Get("/assets/*", h.download)
 	})
 
-	return mux
+	return mux, nil
 }
@@ -2,6 +2,8 @@ package analyze
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strconv"
 
 	"github.com/timohahaa/transcoder/pkg/consts"
@@ -14,6 +16,13 @@ type AudioPreset struct {
 	Preset  *pb.AudioPreset
 }
 
+const (
+	opusSampleRate     = 48000 // the only rate opus works with internally
+	heAACMaxSampleRate = 48000
+)
+
+var eac3SampleRates = []int64{32000, 44100, 48000}
+
 var baseAudioPreset = pb.AudioPreset{
 	Channels:     2,
 	Bitrate:      192 * consts.KBit,
@@ -41,6 +50,12 @@ func CalcAudioPresets(ctx context.Context, info *ffprobe.Info, files []string) (
 		if streams := aInfo.GetAllAudios(); len(streams) > 0 {
 			aStream := streams[0]
 
+			// padding is generated with the source layout
+			preset.ChannelLayout = aStream.ChannelLayout
+			if preset.ChannelLayout == "" && aStream.Channels > 0 {
+				preset.ChannelLayout = fmt.Sprintf("%dc", aStream.Channels)
+			}
+
 			if sampleRate, err := strconv.ParseInt(aStream.SampleRate, 10, 64); err == nil {
 				preset.SampleRate = sampleRate
 			}
@@ -75,3 +90,38 @@ func CalcAudioPresets(ctx context.Context, info *ffprobe.Info, files []string) (
 
 	return audioPresetsMap, nil
 }
+
+// SetAudioRenditions gives every preset its own copy of the ladder
+func SetAudioRenditions(presets map[string]AudioPreset, ladder []*pb.AudioRendition) {
+	for _, p := range presets {
+		for _, r := range ladder {
+			AddAudioRendition(p.Preset, r)
+		}
+	}
+}
+
+// AddAudioRendition appends a copy of the rendition,
+// sample rate is taken from the preset as far as the codec allows
+func AddAudioRendition(preset *pb.AudioPreset, r *pb.AudioRendition) {
+	var sampleRate = preset.SampleRate
+	switch r.Codec {
+	case consts.AudioCodecOpus:
+		sampleRate = opusSampleRate
+	case consts.AudioCodecHEAAC:
+		sampleRate = min(sampleRate, heAACMaxSampleRate)
+	case consts.AudioCodecEAC3:
+		if !slices.Contains(eac3SampleRates, sampleRate) {
+			sampleRate = eac3SampleRates[len(eac3SampleRates)-1]
+		}
+	}
+
+	preset.Renditions = append(preset.Renditions, &pb.AudioRendition{
+		Name:       r.Name,
+		Codec:      r.Codec,
+		Bitrate:    r.Bitrate,
+		Channels:   r.Channels,
+		SampleRate: sampleRate,
+
+		ChannelLayout: r.ChannelLayout,
+	})
+}
@@ -38,6 +38,8 @@ var (
 	// MaxBitrate in presets / 3
 	smallBitrateMap = map[int]map[string]int64{
 		consts.FPS30: {
+			consts.Q144p:  250 * consts.KBit / 3,
+			consts.Q240p:  500 * consts.KBit / 3,
 			consts.Q360p:  1000 * consts.KBit / 3,
 			consts.Q480p:  1500 * consts.KBit / 3,
 			consts.Q720p:  3000 * consts.KBit / 3,
@@ -46,6 +48,8 @@ var (
 			consts.Q2160p: 12000 * consts.KBit / 3,
 		},
 		consts.FPS60: {
+			consts.Q144p:  375 * consts.KBit / 3,
+			consts.Q240p:  750 * consts.KBit / 3,
 			consts.Q360p:  1500 * consts.KBit / 3,
 			consts.Q480p:  2250 * consts.KBit / 3,
 			consts.Q720p:  4500 * consts.KBit / 3,
@@ -0,0 +1,92 @@
+package analyze
+
+import (
+	"strings"
+
+	"github.com/timohahaa/transcoder/pkg/consts"
+	"github.com/timohahaa/transcoder/pkg/ffprobe"
+	pb "github.com/timohahaa/transcoder/proto/composer"
+)
+
+// renditions and sources below it are standard definition
+const hdHeight = 720
+
+// color properties of SDR source
+type Colors struct {
+	Space     string
+	Primaries string
+	Trc       string
+	Range     string // consts.ColorRangeTV or consts.ColorRangePC
+}
+
+// DetectColors returns tags of the source, untagged properties are guessed
+// by size and frame rate the way players do: BT.601 for SD, BT.709 for HD
+func DetectColors(video ffprobe.Stream) Colors {
+	var (
+		w, h   = video.GetResolution()
+		sd     = min(w, h) < hdHeight
+		fps, _ = video.GetFrameRate()
+		pal    = fps == 25 || fps == 50
+		res    = Colors{
+			Space:     tagged(video.ColorSpace),
+			Primaries: tagged(video.ColorPrimaries),
+			Trc:       tagged(video.ColorTransfer),
+			Range:     consts.ColorRangeTV,
+		}
+	)
+
+	if video.ColorRange == consts.ColorRangePC || strings.HasPrefix(video.PixFmt, "yuvj") {
+		res.Range = consts.ColorRangePC
+	}
+
+	switch {
+	case !sd:
+		res.Space = orDefault(res.Space, consts.ColorSpaceBT709)
+		res.Primaries = orDefault(res.Primaries, consts.PrimariesBT709)
+		res.Trc = orDefault(res.Trc, consts.TransferBT709)
+	case pal:
+		res.Space = orDefault(res.Space, consts.ColorSpaceBT470BG)
+		res.Primaries = orDefault(res.Primaries, consts.PrimariesBT470BG)
+		res.Trc = orDefault(res.Trc, consts.TransferSMPTE170M)
+	default:
+		res.Space = orDefault(res.Space, consts.ColorSpaceSMPTE170M)
+		res.Primaries = orDefault(res.Primaries, consts.PrimariesSMPTE170M)
+		res.Trc = orDefault(res.Trc, consts.TransferSMPTE170M)
+	}
+
+	return res
+}
+
+// HD renditions get BT.709 matrix, SD renditions of BT.601 source keep it,
+// SD renditions of HD source stay BT.709 so the ladder is switchable without color shifts,
+// primaries and transfer are not converted, so they are tagged as in the source
+func (c Colors) tag(p *pb.Preset) {
+	p.SourceColorSpace = c.Space
+	p.SourceColorRange = c.Range
+	p.ColorSpace = consts.ColorSpaceBT709
+	if p.Height < hdHeight && isBT601(c.Space) {
+		p.ColorSpace = c.Space
+	}
+	p.ColorPrimaries = c.Primaries
+	p.ColorTrc = c.Trc
+}
+
+func isBT601(space string) bool {
+	return space == consts.ColorSpaceBT470BG || space == consts.ColorSpaceSMPTE170M
+}
+
+// empty for properties ffprobe reports as not set
+func tagged(v string) string {
+	switch v {
+	case "unknown", "unspecified", "reserved":
+		return ""
+	}
+	return v
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,70 @@
+package analyze
+
+import (
+	"context"
+	"math"
+
+	"github.com/timohahaa/transcoder/pkg/ffmpeg"
+	"github.com/timohahaa/transcoder/pkg/ffprobe"
+)
+
+const (
+	complexityProbeHeight = 540
+	// bits per pixel the probe takes for content of average complexity
+	referenceBpp = 0.1
+
+	minComplexityRate = 0.4
+	maxComplexityRate = 1.5
+	minComplexityCRF  = -2
+	maxComplexityCRF  = 3
+)
+
+var complexityProbe = ffmpeg.ComplexityProbe{
+	CRF:     23,
+	Height:  complexityProbeHeight,
+	Samples: 4,
+	Sample:  2,
+}
+
+// CalcComplexity probe encodes samples of the chunk,
+// 1 is content of average complexity, less is easier to encode
+func CalcComplexity(ctx context.Context, chunk string, info *ffprobe.Info) (float64, error) {
+	var (
+		video  = info.GetHighestVideo()
+		w, h   = video.GetResolution()
+		fps, _ = video.GetFrameRate()
+		probe  = complexityProbe
+	)
+
+	if h == 0 || fps == 0 {
+		return 1, nil
+	}
+
+	// never upscaled, rounded down to even the way sourceResolution does
+	probe.Height = min(probe.Height, h) &^ 1
+
+	bitrate, err := ffmpeg.ProbeBitrate(ctx, chunk, info.GetDuration(), probe)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		// scale=-2 keeps aspect and rounds the width down to even
+		width  = (w*probe.Height + h/2) / h &^ 1
+		pixels = float64(width) * float64(probe.Height)
+		bpp    = float64(bitrate) / (pixels * fps)
+	)
+	return bpp / referenceBpp, nil
+}
+
+// maxrate multiplier of the chunk presets, sqrt is used
+// so bitrate doesn't follow complexity estimate too close
+func complexityRate(complexity float64) float64 {
+	return min(max(math.Sqrt(complexity), minComplexityRate), maxComplexityRate)
+}
+
+// CRF offset of the chunk presets, +2 for every halving of complexity:
+// simple content gets smaller files, complex one more bits within maxrate
+func complexityCRF(complexity float64) int32 {
+	return int32(min(max(math.Round(-2*math.Log2(complexity)), minComplexityCRF), maxComplexityCRF))
+}
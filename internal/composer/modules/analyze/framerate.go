@@ -0,0 +1,160 @@
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/timohahaa/transcoder/pkg/consts"
+	"github.com/timohahaa/transcoder/pkg/ffprobe"
+)
+
+const (
+	frameRateTolerance = 0.005 // relative, 23.98 is still 24000/1001
+	vfrTolerance       = 0.01  // relative difference of base and average rates of VFR sources
+	maxFrameRate       = 60    // higher rates (slow motion, etc.) are divided down to it
+	halfRateMaxHeight  = 480   // rungs up to it get half of high frame rates
+	highFrameRate      = 40    // rates above it are high, see presetFpsMap
+)
+
+// standard cinema and broadcast rates, NTSC ones are fractional
+var standardFrameRates = []frameRate{
+	{24000, 1001}, {24, 1}, {25, 1},
+	{30000, 1001}, {30, 1},
+	{48, 1}, {50, 1},
+	{60000, 1001}, {60, 1},
+}
+
+type frameRate struct {
+	Num, Den int
+}
+
+// ffmpeg notation, e.g. 30000/1001
+func (r frameRate) String() string {
+	return fmt.Sprintf("%d/%d", r.Num, r.Den)
+}
+
+func (r frameRate) Float() float64 {
+	return float64(r.Num) / float64(r.Den)
+}
+
+func (r frameRate) high() bool {
+	return r.Float() > highFrameRate
+}
+
+// keeps every other frame, 60000/1001 becomes 30000/1001
+func (r frameRate) half() frameRate {
+	if r.Num%2 == 0 {
+		return frameRate{r.Num / 2, r.Den}
+	}
+	return frameRate{r.Num, r.Den * 2}
+}
+
+func (r frameRate) double() frameRate {
+	if r.Den%2 == 0 {
+		return frameRate{r.Num, r.Den / 2}
+	}
+	return frameRate{r.Num * 2, r.Den}
+}
+
+// of a rung by its height, low rungs of high frame rate sources get half of it
+func (r frameRate) forHeight(height int32) frameRate {
+	if r.high() && height <= halfRateMaxHeight {
+		return r.half()
+	}
+	return r
+}
+
+func (r frameRate) reduce() frameRate {
+	var a, b = r.Num, r.Den
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return frameRate{r.Num / a, r.Den / a}
+}
+
+// ffprobe notation, 30000/1001 or 25, false for 0/0 and garbage
+func parseFrameRate(s string) (frameRate, bool) {
+	num, den, ok := strings.Cut(s, "/")
+	if !ok {
+		den = "1"
+	}
+
+	n, err := strconv.Atoi(num)
+	if err != nil || n <= 0 {
+		return frameRate{}, false
+	}
+	d, err := strconv.Atoi(den)
+	if err != nil || d <= 0 {
+		return frameRate{}, false
+	}
+	return frameRate{n, d}.reduce(), true
+}
+
+// sourceFrameRate is the constant rate renditions are encoded with:
+// native one of CFR sources, close to standard ones are snapped to them,
+// high ones are divided down to maxFrameRate,
+// VFR sources (phone recordings) get the standard rate closest to their average one,
+// fieldRate doubles the rate before the limit, a frame per field of interlaced source
+func sourceFrameRate(video ffprobe.Stream, fieldRate bool) frameRate {
+	var (
+		base, baseOk = parseFrameRate(video.RFrameRate)
+		avg, avgOk   = parseFrameRate(video.AvgFrameRate)
+		vfr          bool
+	)
+	switch {
+	case !baseOk && !avgOk: // we dunno, the old default
+		return frameRate{consts.FPS30, 1}
+	case !baseOk:
+		base = avg
+	case avgOk && math.Abs(base.Float()-avg.Float()) > avg.Float()*vfrTolerance:
+		base, vfr = avg, true
+	}
+	if fieldRate {
+		base = base.double()
+	}
+
+	// divided by a whole number, so frames are dropped evenly
+	if limit := maxFrameRate * (1 + frameRateTolerance); base.Float() > limit {
+		var n = int(math.Ceil(base.Float() / limit))
+		base = frameRate{base.Num, base.Den * n}.reduce()
+	}
+
+	var std, diff = closestStandardFrameRate(base.Float())
+	if diff <= frameRateTolerance || vfr {
+		return std
+	}
+	return base
+}
+
+// relative difference is returned as well
+func closestStandardFrameRate(fps float64) (frameRate, float64) {
+	var (
+		res  = standardFrameRates[0]
+		diff = math.Inf(1)
+	)
+	for _, r := range standardFrameRates {
+		if d := math.Abs(r.Float()-fps) / r.Float(); d < diff {
+			res, diff = r, d
+		}
+	}
+	return res, diff
+}
+
+// key of presetFpsMap and codec ladders
+func fpsClass(r frameRate) int {
+	if r.high() {
+		return consts.FPS60
+	}
+	return consts.FPS30
+}
+
+// of a preset FPS, 30 fps one if it can't be parsed
+func presetFpsClass(fps string) int {
+	r, ok := parseFrameRate(fps)
+	if !ok {
+		return consts.FPS30
+	}
+	return fpsClass(r)
+}
@@ -0,0 +1,135 @@
+package analyze
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/timohahaa/transcoder/pkg/consts"
+	"github.com/timohahaa/transcoder/pkg/ffprobe"
+	pb "github.com/timohahaa/transcoder/proto/composer"
+)
+
+// 10-bit HDR renditions take more bits than 8-bit SDR ones of the same quality
+const hdrRateMultiplier = 1.25
+
+const (
+	sideDataMastering    = "Mastering display metadata"
+	sideDataContentLight = "Content light level metadata"
+)
+
+type HDRSource struct {
+	Transfer  string          // consts.TransferPQ or consts.TransferHLG
+	Mastering *pb.HDRMetadata // nil if the source has none
+}
+
+// DetectHDR returns nil for SDR sources, HDR is told by transfer characteristics,
+// BT.2020 primaries alone are wide gamut SDR
+func DetectHDR(ctx context.Context, path string, info *ffprobe.Info) (*HDRSource, error) {
+	var video = info.GetHighestVideo()
+	switch video.ColorTransfer {
+	case consts.TransferPQ, consts.TransferHLG:
+	default:
+		return nil, nil
+	}
+
+	var (
+		hdr       = &HDRSource{Transfer: video.ColorTransfer}
+		sideData  = video.SideDataList
+		mastering = hdrMetadata(sideData)
+	)
+	if mastering == nil {
+		frameSideData, err := ffprobe.FirstFrameSideData(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		mastering = hdrMetadata(frameSideData)
+	}
+	hdr.Mastering = mastering
+
+	return hdr, nil
+}
+
+// nil if there is no mastering display metadata
+func hdrMetadata(sideData []ffprobe.SideData) *pb.HDRMetadata {
+	var res *pb.HDRMetadata
+	for _, sd := range sideData {
+		if sd.Type == sideDataMastering {
+			res = &pb.HDRMetadata{
+				RedX:         rational(sd.RedX),
+				RedY:         rational(sd.RedY),
+				GreenX:       rational(sd.GreenX),
+				GreenY:       rational(sd.GreenY),
+				BlueX:        rational(sd.BlueX),
+				BlueY:        rational(sd.BlueY),
+				WhiteX:       rational(sd.WhitePointX),
+				WhiteY:       rational(sd.WhitePointY),
+				MaxLuminance: rational(sd.MaxLuminance),
+				MinLuminance: rational(sd.MinLuminance),
+			}
+		}
+	}
+	if res == nil {
+		return nil
+	}
+
+	for _, sd := range sideData {
+		if sd.Type == sideDataContentLight {
+			res.MaxCLL = int32(sd.MaxContent)
+			res.MaxFALL = int32(sd.MaxAverage)
+		}
+	}
+	return res
+}
+
+// ffprobe prints mastering display values as 34000/50000
+func rational(s string) float32 {
+	num, den, ok := strings.Cut(s, "/")
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0
+	}
+	if !ok {
+		return float32(n)
+	}
+
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil || d == 0 {
+		return 0
+	}
+	return float32(n / d)
+}
+
+// 10-bit copies of H.264 presets in codec keeping transfer of the source
+func calcHDRPresets(h264 []*pb.Preset, codec string, hdr *HDRSource) []*pb.Preset {
+	var (
+		base    = h264
+		profile = consts.ProfileHigh10
+	)
+	switch codec {
+	case consts.CodecHEVC:
+		base = calcCodecPresets(h264, []string{codec})
+		profile = consts.ProfileMain10
+	case consts.CodecAV1:
+		// main profile is 10-bit already
+		base = calcCodecPresets(h264, []string{codec})
+		profile = consts.ProfileMain
+	}
+
+	var res = make([]*pb.Preset, 0, len(base))
+	for _, p := range base {
+		var preset = p.Copy()
+		preset.HDR = true
+		preset.Profile = profile
+		preset.MaxBitRate = int64(math.Round(float64(preset.MaxBitRate) * hdrRateMultiplier))
+		preset.Bufsize = preset.MaxBitRate * 2
+		preset.ColorTrc = hdr.Transfer
+		preset.ColorSpace = consts.ColorSpaceBT2020NC
+		preset.ColorPrimaries = consts.PrimariesBT2020
+		preset.Mastering = hdr.Mastering.Copy()
+
+		res = append(res, preset)
+	}
+	return res
+}
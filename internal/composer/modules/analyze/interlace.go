@@ -0,0 +1,31 @@
+package analyze
+
+import (
+	"context"
+
+	"github.com/timohahaa/transcoder/pkg/ffmpeg"
+	"github.com/timohahaa/transcoder/pkg/ffprobe"
+)
+
+// idet sample, openings of broadcast recordings are often progressive
+// slates or black frames, so the sample is taken further into the source
+const (
+	idetSampleFrames = 1000
+	idetSampleOffset = 0.1 // of the duration
+	idetMaxOffset    = 300 // seconds
+)
+
+// DetectInterlace classifies frames of a sample of the source
+func DetectInterlace(ctx context.Context, path string, info *ffprobe.Info) (ffmpeg.Interlace, error) {
+	var (
+		fps, _   = info.GetHighestVideo().GetFrameRate()
+		duration = info.GetDuration()
+		start    = min(duration*idetSampleOffset, idetMaxOffset)
+	)
+	// sample is taken from the start if the rest is shorter than it
+	if fps > 0 && duration-start < idetSampleFrames/fps {
+		start = 0
+	}
+
+	return ffmpeg.DetectInterlace(ctx, path, start, idetSampleFrames)
+}
@@ -0,0 +1,48 @@
+package analyze
+
+import (
+	"context"
+
+	"github.com/timohahaa/transcoder/pkg/ffmpeg"
+	pb "github.com/timohahaa/transcoder/proto/composer"
+)
+
+// CalcLoudness runs the first loudnorm pass over unmuxed audio file,
+// encoder applies the second one with the returned preset
+func CalcLoudness(ctx context.Context, file string, target ffmpeg.LoudnessTarget) (*pb.LoudnessPreset, error) {
+	measured, err := ffmpeg.MeasureLoudness(ctx, file, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return loudnessPreset(target, measured), nil
+}
+
+// CalcDownmixLoudness is CalcLoudness of the file downmixed to channels
+func CalcDownmixLoudness(
+	ctx context.Context,
+	file string,
+	downmix ffmpeg.Downmix,
+	channels int,
+	target ffmpeg.LoudnessTarget,
+) (*pb.LoudnessPreset, error) {
+	measured, err := ffmpeg.MeasureDownmixLoudness(ctx, file, downmix, channels, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return loudnessPreset(target, measured), nil
+}
+
+func loudnessPreset(target ffmpeg.LoudnessTarget, measured ffmpeg.Loudness) *pb.LoudnessPreset {
+	return &pb.LoudnessPreset{
+		TargetI:        float32(target.I),
+		TargetTP:       float32(target.TP),
+		TargetLRA:      float32(target.LRA),
+		MeasuredI:      float32(measured.I),
+		MeasuredTP:     float32(measured.TP),
+		MeasuredLRA:    float32(measured.LRA),
+		MeasuredThresh: float32(measured.Thresh),
+		Offset:         float32(measured.Offset),
+	}
+}
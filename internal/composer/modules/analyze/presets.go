@@ -2,7 +2,9 @@ package analyze
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"strconv"
 
 	"github.com/timohahaa/transcoder/pkg/consts"
 	"github.com/timohahaa/transcoder/pkg/ffmpeg"
@@ -11,95 +13,109 @@ import (
 )
 
 type ChunkPresets struct {
-	Ffprobe *ffprobe.Info
-	Presets []*pb.Preset
+	Ffprobe    *ffprobe.Info
+	Presets    []*pb.Preset
+	Complexity float64 // 0 if not measured
 }
 
-func CalcChunkPresets(info *ffprobe.Info, chunks []ffmpeg.Chunk) (map[string]ChunkPresets, error) {
+// what the ladder is made of besides H.264 SDR renditions
+type LadderOpts struct {
+	Codecs   []string                     // additional ladders next to H.264 one, consts.CodecHEVC or consts.CodecAV1
+	GOP      func(rendition string) int32 // GOP seconds of a rendition by its name
+	HDR      *HDRSource                   // nil for SDR sources, renditions are tone mapped otherwise
+	HDRCodec string                       // 10-bit ladder keeping HDR of the source, none if empty
+	// nil for progressive sources, field rate doubles the frame rate of every rendition
+	Deinterlace *pb.DeinterlacePreset
+}
+
+// CalcChunkPresets returns warnings about chunks encoded with fallback presets
+func CalcChunkPresets(
+	ctx context.Context,
+	info *ffprobe.Info,
+	chunks []ffmpeg.Chunk,
+	opts LadderOpts,
+) (map[string]ChunkPresets, []string, error) {
 	if IsSmallBitrate(info) {
-		return calcChunkPresetsSmall(info, chunks)
+		res, err := calcChunkPresetsSmall(ctx, info, chunks, opts)
+		return res, nil, err
 	}
-	return calcChunkPresets(info, chunks)
+	return calcChunkPresets(ctx, info, chunks, opts)
 }
 
-// optimize bitrate for every chunk to minimize output bitrate while preserving quality
-func calcChunkPresets(info *ffprobe.Info, chunks []ffmpeg.Chunk) (map[string]ChunkPresets, error) {
+// optimize bitrate for every chunk to minimize output bitrate while preserving quality,
+// maxrate and CRF of every rendition follow the chunk complexity
+func calcChunkPresets(
+	ctx context.Context,
+	info *ffprobe.Info,
+	chunks []ffmpeg.Chunk,
+	opts LadderOpts,
+) (map[string]ChunkPresets, []string, error) {
 	var (
-		high              = info.GetHighestVideo()
-		encodeQualities   = lessOrEqQualities(high.GetQuality())
-		basePresets       = calcBasePresets(info, encodeQualities)
-		bitrateLadder     = bitrateLadderMap[high.GetQuality()]
-		bitrateMultiplier = calcBitrateMultiplier(info)
-		chunkPresetsMap   = make(map[string]ChunkPresets, len(chunks))
+		high            = info.GetHighestVideo()
+		encodeQualities = lessOrEqQualities(ladderQuality(high))
+		basePresets     = calcBasePresets(info, encodeQualities, opts)
+		chunkPresetsMap = make(map[string]ChunkPresets, len(chunks))
+		warnings        []string
 	)
 
 	for _, chunk := range chunks {
-		chunkInfo, err := ffprobe.GetInfo(context.Background(), chunk.Path)
+		chunkInfo, err := ffprobe.GetInfo(ctx, chunk.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// presets of average content are good enough, the task is not failed because of it
+		complexity, err := CalcComplexity(ctx, chunk.Path, chunkInfo)
 		if err != nil {
-			return nil, err
+			if ctx.Err() != nil {
+				return nil, nil, err
+			}
+			warnings = append(warnings, fmt.Sprintf(
+				"chunk %s is encoded with average complexity presets: %v", chunk.Name, err,
+			))
+			complexity = 1
 		}
 
 		var (
-			chunkBitrate = chunkInfo.GetHighestVideo().BitRate
+			rate         = complexityRate(complexity)
+			crfOffset    = complexityCRF(complexity)
 			chunkPresets = make([]*pb.Preset, 0, len(basePresets))
 		)
 
-		// for some codecs like prores
-		if chunkBitrate == 0 {
-			chunkBitrate = chunkInfo.Format.BitRate
-		}
-
 		for _, vp := range basePresets {
 			preset := vp.Copy()
-			bitrare := float64(chunkBitrate) * bitrateLadder[preset.Quality]
-
-			if bitrare <= float64(preset.MaxBitRate) {
-				preset.MaxBitRate = int64(math.Ceil(bitrare))
-			}
 
-			preset.MaxBitRate = int64(math.Round(float64(preset.MaxBitRate) * bitrateMultiplier))
+			preset.MaxBitRate = int64(math.Round(float64(preset.MaxBitRate) * rate))
 			preset.Bufsize = preset.MaxBitRate * 2
+			preset.CRF += crfOffset
 
 			chunkPresets = append(chunkPresets, preset)
 		}
 		chunkPresetsMap[chunk.Name] = ChunkPresets{
-			Ffprobe: chunkInfo,
-			Presets: chunkPresets,
+			Ffprobe:    chunkInfo,
+			Presets:    chunkPresets,
+			Complexity: complexity,
 		}
 	}
 
-	return chunkPresetsMap, nil
+	return chunkPresetsMap, warnings, nil
 }
 
-func calcBasePresets(info *ffprobe.Info, encodeQualities []string) []*pb.Preset {
+func calcBasePresets(info *ffprobe.Info, encodeQualities []string, opts LadderOpts) []*pb.Preset {
 	var (
-		highVideo  = info.GetHighestVideo()
-		fps, _     = highVideo.GetFrameRate()
-		fpsStr     = highVideo.RFrameRate
-		presets    = map[string]preset{}
-		gopSeconds = calcGOPSize(info)
+		highVideo = info.GetHighestVideo()
+		rate      = sourceFrameRate(highVideo, opts.Deinterlace.GetFieldRate())
+		presets   = map[string]preset{}
 	)
 
-	// cap fps
-	switch {
-	case fps <= 40:
-		fps = 30
-		fpsStr = "30/1"
-	default: // > 45 => cap at 60
-		fps = 60
-		fpsStr = "60/1"
-	}
-
-	// base presets
-	var originalPresets = map[string]preset{}
-	if fps <= 40 {
-		originalPresets = presetFpsMap[consts.FPS30]
-	} else {
-		originalPresets = presetFpsMap[consts.FPS60]
-	}
-
+	// base presets, bitrates depend on the frame rate of the rung
 	for _, qual := range encodeQualities {
-		presets[qual] = originalPresets[qual].copy()
+		var (
+			fps    = rate.forHeight(presetFpsMap[consts.FPS30][qual].Height)
+			preset = presetFpsMap[fpsClass(fps)][qual].copy()
+		)
+		preset.FPS = fps.String()
+		presets[qual] = preset
 	}
 
 	var (
@@ -113,29 +129,59 @@ func calcBasePresets(info *ffprobe.Info, encodeQualities []string) []*pb.Preset
 	// if GPU decoding is used - should also calculate transpose filter
 	transposeFilter = ""
 
-	var res []*pb.Preset
+	var (
+		res        []*pb.Preset
+		top        = topQuality(encodeQualities)
+		srcW, srcH = sourceResolution(origW, origH)
+	)
 	for _, preset := range presets {
-		preset.FPS = fpsStr
 		preset.IsVertical = isVertical
 		preset.Transpose = transposeFilter
-		preset.GOPSeconds = gopSeconds
 
-		preset.setResolution(origW, origH)
+		// a source falling between rungs keeps its exact size in the top rendition
+		if preset.Quality == top && srcW <= preset.WidthMax && srcH <= preset.Height {
+			preset.setSourceResolution(origW, origH)
+		} else {
+			preset.setResolution(origW, origH)
+		}
+		// never upscaled
+		if preset.Width > srcW || preset.Height > srcH {
+			continue
+		}
 
-		res = append(res, preset.toProto())
+		var p = preset.toProto()
+		p.Deinterlace = opts.Deinterlace.Copy()
+		res = append(res, p)
 	}
 
-	return res
-}
+	var h264 = res
+	res = append(res, calcCodecPresets(h264, opts.Codecs)...)
+
+	if opts.HDR == nil {
+		var colors = DetectColors(highVideo)
+		for _, p := range res {
+			colors.tag(p)
+		}
+	} else {
+		for _, p := range res {
+			p.Tonemap = opts.HDR.Transfer
+			p.ColorTrc = consts.TransferBT709
+			p.ColorSpace = consts.ColorSpaceBT709
+			p.ColorPrimaries = consts.PrimariesBT709
+		}
+		if opts.HDRCodec != "" {
+			res = append(res, calcHDRPresets(h264, opts.HDRCodec, opts.HDR)...)
+		}
+	}
 
-// gop size in seconds
-func calcGOPSize(_ *ffprobe.Info) int32 {
-	// for now just return 4 seconds
-	return 4
+	for _, p := range res {
+		p.GOPSeconds = opts.GOP(p.Name())
+	}
+	return res
 }
 
-// because videos are encoded to H264
-// bitrates need to be readjusted depending on source codec
+// small bitrate sources cap maxrate with their own bitrate,
+// it needs to be readjusted depending on source codec
 // for example, h265 generally gives 30-50% smaller file sizes compared to H264
 // so in that case we need to up bitrate for H265 -> H264 by 30-50%
 func calcBitrateMultiplier(info *ffprobe.Info) float64 {
@@ -149,6 +195,36 @@ func calcBitrateMultiplier(info *ffprobe.Info) float64 {
 	}
 }
 
+// ladderQuality is the smallest rung fitting the source in both dimensions,
+// so wide sources are not squeezed into the rung of their height
+func ladderQuality(video ffprobe.Stream) string {
+	var (
+		w, h       = video.GetRenderResolution()
+		srcW, srcH = sourceResolution(w, h)
+		rungs      = lessOrEqQualities(consts.Q2160p)
+	)
+	for i := len(rungs) - 1; i >= 0; i-- {
+		if r := presetFpsMap[consts.FPS30][rungs[i]]; srcW <= r.WidthMax && srcH <= r.Height {
+			return rungs[i]
+		}
+	}
+	return consts.Q2160p
+}
+
+// highest of qualities, they are numeric
+func topQuality(qualities []string) string {
+	var (
+		res    string
+		resNum int
+	)
+	for _, q := range qualities {
+		if num, _ := strconv.Atoi(q); num > resNum {
+			res, resNum = q, num
+		}
+	}
+	return res
+}
+
 func lessOrEqQualities(quality string) []string {
 	switch quality {
 	case consts.Q2160p:
@@ -159,6 +235,8 @@ func lessOrEqQualities(quality string) []string {
 			consts.Q720p,
 			consts.Q480p,
 			consts.Q360p,
+			consts.Q240p,
+			consts.Q144p,
 		}
 	case consts.Q1440p:
 		return []string{
@@ -167,6 +245,8 @@ func lessOrEqQualities(quality string) []string {
 			consts.Q720p,
 			consts.Q480p,
 			consts.Q360p,
+			consts.Q240p,
+			consts.Q144p,
 		}
 	case consts.Q1080p:
 		return []string{
@@ -174,21 +254,38 @@ func lessOrEqQualities(quality string) []string {
 			consts.Q720p,
 			consts.Q480p,
 			consts.Q360p,
+			consts.Q240p,
+			consts.Q144p,
 		}
 	case consts.Q720p:
 		return []string{
 			consts.Q720p,
 			consts.Q480p,
 			consts.Q360p,
+			consts.Q240p,
+			consts.Q144p,
 		}
 	case consts.Q480p:
 		return []string{
 			consts.Q480p,
 			consts.Q360p,
+			consts.Q240p,
+			consts.Q144p,
 		}
 	case consts.Q360p:
 		return []string{
 			consts.Q360p,
+			consts.Q240p,
+			consts.Q144p,
+		}
+	case consts.Q240p:
+		return []string{
+			consts.Q240p,
+			consts.Q144p,
+		}
+	case consts.Q144p:
+		return []string{
+			consts.Q144p,
 		}
 	default:
 		return nil
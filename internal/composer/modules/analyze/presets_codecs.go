@@ -0,0 +1,116 @@
+package analyze
+
+import (
+	"github.com/timohahaa/transcoder/pkg/consts"
+	pb "github.com/timohahaa/transcoder/proto/composer"
+)
+
+// ladder encoded next to H.264 one with the same qualities,
+// resolution, fps and GOP are taken from H.264 presets
+type codecLadder struct {
+	Profile string
+	CRF     int32
+	// source bitrate cap of small bitrate sources relative to H.264
+	Efficiency float64
+	// maxrates for content of average complexity and levels by fps and quality
+	Rungs map[int]map[string]codecRung
+}
+
+type codecRung struct {
+	MaxBitRate int64
+	Level      string
+}
+
+var codecLadders = map[string]codecLadder{
+	consts.CodecHEVC: {
+		Profile:    consts.ProfileMain,
+		CRF:        28,
+		Efficiency: 0.6,
+		Rungs: map[int]map[string]codecRung{
+			consts.FPS30: {
+				consts.Q144p:  {MaxBitRate: 150 * consts.KBit, Level: consts.Level_3_0},
+				consts.Q240p:  {MaxBitRate: 300 * consts.KBit, Level: consts.Level_3_0},
+				consts.Q360p:  {MaxBitRate: 600 * consts.KBit, Level: consts.Level_3_0},
+				consts.Q480p:  {MaxBitRate: 900 * consts.KBit, Level: consts.Level_3_1},
+				consts.Q720p:  {MaxBitRate: 1800 * consts.KBit, Level: consts.Level_4_0},
+				consts.Q1080p: {MaxBitRate: 3600 * consts.KBit, Level: consts.Level_4_1},
+				consts.Q1440p: {MaxBitRate: 5400 * consts.KBit, Level: consts.Level_5_0},
+				consts.Q2160p: {MaxBitRate: 8000 * consts.KBit, Level: consts.Level_5_1},
+			},
+			consts.FPS60: {
+				consts.Q144p:  {MaxBitRate: 225 * consts.KBit, Level: consts.Level_3_0},
+				consts.Q240p:  {MaxBitRate: 450 * consts.KBit, Level: consts.Level_3_0},
+				consts.Q360p:  {MaxBitRate: 900 * consts.KBit, Level: consts.Level_3_1},
+				consts.Q480p:  {MaxBitRate: 1350 * consts.KBit, Level: consts.Level_4_0},
+				consts.Q720p:  {MaxBitRate: 2700 * consts.KBit, Level: consts.Level_4_1},
+				consts.Q1080p: {MaxBitRate: 5400 * consts.KBit, Level: consts.Level_4_1},
+				consts.Q1440p: {MaxBitRate: 8100 * consts.KBit, Level: consts.Level_5_1},
+				consts.Q2160p: {MaxBitRate: 12000 * consts.KBit, Level: consts.Level_5_2},
+			},
+		},
+	},
+	consts.CodecAV1: {
+		Profile:    consts.ProfileMain,
+		CRF:        35,
+		Efficiency: 0.5,
+		Rungs: map[int]map[string]codecRung{
+			consts.FPS30: {
+				consts.Q144p:  {MaxBitRate: 125 * consts.KBit, Level: consts.Level_3_0},
+				consts.Q240p:  {MaxBitRate: 250 * consts.KBit, Level: consts.Level_3_0},
+				consts.Q360p:  {MaxBitRate: 500 * consts.KBit, Level: consts.Level_3_0},
+				consts.Q480p:  {MaxBitRate: 750 * consts.KBit, Level: consts.Level_3_0},
+				consts.Q720p:  {MaxBitRate: 1500 * consts.KBit, Level: consts.Level_3_1},
+				consts.Q1080p: {MaxBitRate: 3000 * consts.KBit, Level: consts.Level_4_0},
+				consts.Q1440p: {MaxBitRate: 4500 * consts.KBit, Level: consts.Level_5_0},
+				consts.Q2160p: {MaxBitRate: 7000 * consts.KBit, Level: consts.Level_5_0},
+			},
+			consts.FPS60: {
+				consts.Q144p:  {MaxBitRate: 190 * consts.KBit, Level: consts.Level_3_0},
+				consts.Q240p:  {MaxBitRate: 375 * consts.KBit, Level: consts.Level_3_0},
+				consts.Q360p:  {MaxBitRate: 750 * consts.KBit, Level: consts.Level_3_0},
+				consts.Q480p:  {MaxBitRate: 1100 * consts.KBit, Level: consts.Level_3_1},
+				consts.Q720p:  {MaxBitRate: 2250 * consts.KBit, Level: consts.Level_4_0},
+				consts.Q1080p: {MaxBitRate: 4500 * consts.KBit, Level: consts.Level_4_1},
+				consts.Q1440p: {MaxBitRate: 6750 * consts.KBit, Level: consts.Level_5_1},
+				consts.Q2160p: {MaxBitRate: 10500 * consts.KBit, Level: consts.Level_5_1},
+			},
+		},
+	},
+}
+
+// source bitrate cap multiplier of output codec, H.264 is the reference
+func codecEfficiency(codec string) float64 {
+	if l, ok := codecLadders[codec]; ok {
+		return l.Efficiency
+	}
+	return 1
+}
+
+// copies of H.264 presets for every additional codec
+func calcCodecPresets(h264 []*pb.Preset, codecs []string) []*pb.Preset {
+	var res []*pb.Preset
+	for _, codec := range codecs {
+		ladder, ok := codecLadders[codec]
+		if !ok {
+			continue
+		}
+
+		for _, p := range h264 {
+			rung, ok := ladder.Rungs[presetFpsClass(p.FPS)][p.Quality]
+			if !ok {
+				continue
+			}
+
+			var preset = p.Copy()
+			preset.Codec = codec
+			preset.Profile = ladder.Profile
+			preset.Level = rung.Level
+			preset.CRF = ladder.CRF
+			preset.MaxBitRate = rung.MaxBitRate
+			preset.Bufsize = rung.MaxBitRate * 2
+
+			res = append(res, preset)
+		}
+	}
+	return res
+}
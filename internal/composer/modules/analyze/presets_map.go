@@ -59,8 +59,7 @@ func (p *preset) setResolution(origW, origH int) {
 			p.Width = calcW
 
 			if calcW > int32(origW) {
-				p.Width = int32(origW)
-				p.Height = int32(origH)
+				p.setSourceResolution(origW, origH)
 			}
 			return
 		}
@@ -80,12 +79,24 @@ func (p *preset) setResolution(origW, origH int) {
 		p.Height = calcH
 
 		if calcH > int32(origH) {
-			p.Width = int32(origW)
-			p.Height = int32(origH)
+			p.setSourceResolution(origW, origH)
 		}
 	}
 }
 
+// exact size of the source, odd dimensions are rounded down, yuv420p can't have them
+func (p *preset) setSourceResolution(origW, origH int) {
+	p.Width, p.Height = sourceResolution(origW, origH)
+}
+
+// of landscape orientation, the same way presets are
+func sourceResolution(origW, origH int) (width, height int32) {
+	if origH > origW {
+		origW, origH = origH, origW
+	}
+	return int32(origW &^ 1), int32(origH &^ 1)
+}
+
 func (p preset) copy() preset {
 	return preset{
 		Quality:        p.Quality,
@@ -135,6 +146,48 @@ func (p preset) toProto() *pb.Preset {
 
 var presetFpsMap = map[int]map[string]preset{
 	consts.FPS30: {
+		consts.Q144p: {
+			Quality:        consts.Q144p,
+			MaxBitRate:     250 * consts.KBit,
+			MinBitRate:     0,
+			FPS:            strconv.Itoa(consts.FPS30),
+			Codec:          consts.CodecH264,
+			Bufsize:        500 * consts.KBit,
+			GOPSeconds:     2,
+			Profile:        consts.ProfileMain,
+			Level:          consts.Level_3_0,
+			CRF:            23,
+			ColorTrc:       "",
+			ColorSpace:     "",
+			ColorPrimaries: "",
+			Tune:           "",
+			Transpose:      "",
+			IsVertical:     false,
+			Width:          -2, // -2 means ffmpeg decides automaticaly
+			WidthMax:       256,
+			Height:         144,
+		},
+		consts.Q240p: {
+			Quality:        consts.Q240p,
+			MaxBitRate:     500 * consts.KBit,
+			MinBitRate:     0,
+			FPS:            strconv.Itoa(consts.FPS30),
+			Codec:          consts.CodecH264,
+			Bufsize:        1000 * consts.KBit,
+			GOPSeconds:     2,
+			Profile:        consts.ProfileMain,
+			Level:          consts.Level_3_0,
+			CRF:            23,
+			ColorTrc:       "",
+			ColorSpace:     "",
+			ColorPrimaries: "",
+			Tune:           "",
+			Transpose:      "",
+			IsVertical:     false,
+			Width:          -2, // -2 means ffmpeg decides automaticaly
+			WidthMax:       426,
+			Height:         240,
+		},
 		consts.Q360p: {
 			Quality:        consts.Q360p,
 			MaxBitRate:     1000 * consts.KBit,
@@ -263,6 +316,48 @@ var presetFpsMap = map[int]map[string]preset{
 		},
 	},
 	consts.FPS60: {
+		consts.Q144p: {
+			Quality:        consts.Q144p,
+			MaxBitRate:     375 * consts.KBit,
+			MinBitRate:     0,
+			FPS:            strconv.Itoa(consts.FPS60),
+			Codec:          consts.CodecH264,
+			Bufsize:        750 * consts.KBit,
+			GOPSeconds:     2,
+			Profile:        consts.ProfileMain,
+			Level:          consts.Level_3_0,
+			CRF:            23,
+			ColorTrc:       "",
+			ColorSpace:     "",
+			ColorPrimaries: "",
+			Tune:           "",
+			Transpose:      "",
+			IsVertical:     false,
+			Width:          -2, // -2 means ffmpeg decides automaticaly
+			WidthMax:       256,
+			Height:         144,
+		},
+		consts.Q240p: {
+			Quality:        consts.Q240p,
+			MaxBitRate:     750 * consts.KBit,
+			MinBitRate:     0,
+			FPS:            strconv.Itoa(consts.FPS60),
+			Codec:          consts.CodecH264,
+			Bufsize:        1500 * consts.KBit,
+			GOPSeconds:     2,
+			Profile:        consts.ProfileMain,
+			Level:          consts.Level_3_0,
+			CRF:            23,
+			ColorTrc:       "",
+			ColorSpace:     "",
+			ColorPrimaries: "",
+			Tune:           "",
+			Transpose:      "",
+			IsVertical:     false,
+			Width:          -2, // -2 means ffmpeg decides automaticaly
+			WidthMax:       426,
+			Height:         240,
+		},
 		consts.Q360p: {
 			Quality:        consts.Q360p,
 			MaxBitRate:     1500 * consts.KBit,
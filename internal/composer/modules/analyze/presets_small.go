@@ -13,12 +13,12 @@ import (
 )
 
 // for small bitrate videos do not optimize individual chunk bitrate
-func calcChunkPresetsSmall(info *ffprobe.Info, chunks []ffmpeg.Chunk) (map[string]ChunkPresets, error) {
+func calcChunkPresetsSmall(ctx context.Context, info *ffprobe.Info, chunks []ffmpeg.Chunk, opts LadderOpts) (map[string]ChunkPresets, error) {
 	var (
 		high                = info.GetHighestVideo()
-		baseEncodeQualities = lessOrEqQualities(high.GetQuality())
+		baseEncodeQualities = lessOrEqQualities(ladderQuality(high))
 		encodeQualities     = smallBitrareEncodeQualities(baseEncodeQualities)
-		basePresets         = calcBasePresets(info, encodeQualities)
+		basePresets         = calcBasePresets(info, encodeQualities, opts)
 		bitrateMultiplier   = calcBitrateMultiplier(info)
 		chunkPresetsMap     = make(map[string]ChunkPresets, len(chunks))
 		bitrate             = high.BitRate
@@ -30,7 +30,7 @@ func calcChunkPresetsSmall(info *ffprobe.Info, chunks []ffmpeg.Chunk) (map[strin
 	}
 
 	for _, chunk := range chunks {
-		chunkInfo, err := ffprobe.GetInfo(context.Background(), chunk.Path)
+		chunkInfo, err := ffprobe.GetInfo(ctx, chunk.Path)
 		if err != nil {
 			return nil, err
 		}
@@ -39,8 +39,8 @@ func calcChunkPresetsSmall(info *ffprobe.Info, chunks []ffmpeg.Chunk) (map[strin
 		for _, vp := range basePresets {
 			preset := vp.Copy()
 
-			if bitrate <= preset.MaxBitRate {
-				preset.MaxBitRate = bitrate
+			if codecBitrate := int64(math.Round(float64(bitrate) * codecEfficiency(preset.Codec))); codecBitrate <= preset.MaxBitRate {
+				preset.MaxBitRate = codecBitrate
 			}
 
 			preset.MaxBitRate = int64(math.Round(float64(preset.MaxBitRate) * bitrateMultiplier))
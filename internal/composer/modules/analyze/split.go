@@ -2,25 +2,106 @@ package analyze
 
 import (
 	"math"
+	"slices"
 
 	"github.com/timohahaa/transcoder/pkg/ffprobe"
 )
 
-const (
-	oneChunkDuration    = 60  // in seconds
-	splitInHalfDuration = 120 // in seconds
-)
-
-func CalcChunkSize(info *ffprobe.Info) (_ int, needSplit bool) {
+// CalcChunkSize returns chunk duration in seconds, sources up to two chunks long
+// are split in half, rounded up to gop so chunks are whole GOPs
+func CalcChunkSize(info *ffprobe.Info, chunk, gop int) (_ int, needSplit bool) {
 	var duration = info.GetDuration()
 
-	if duration <= float64(oneChunkDuration) {
+	if duration <= float64(chunk) {
 		return 0, false
 	}
 
-	if duration <= float64(splitInHalfDuration) {
-		return int(math.Ceil(duration / 2)), true
+	if duration <= float64(2*chunk) {
+		return int(math.Ceil(duration/float64(2*gop))) * gop, true
+	}
+
+	return chunk, true
+}
+
+// CalcSceneCuts picks a scene change for every cut so chunks are between minChunk
+// and maxChunk seconds, the one closest to the middle of the range is preferred,
+// a chunk without scene changes in range is cut at the keyframe closest to its middle.
+// Stream copy cuts at keyframes only, so every scene change is moved to the first
+// keyframe after it before the bounds are checked, without keyframes times are kept
+func CalcSceneCuts(scenes, keyframes []float64, duration, minChunk, maxChunk float64) []float64 {
+	var (
+		cuts   []float64
+		start  float64
+		target = (minChunk + maxChunk) / 2
+	)
+
+	for duration-start > maxChunk {
+		var (
+			lo = start + minChunk
+			// the last chunk is not shorter than minChunk either
+			hi   = min(start+maxChunk, duration-minChunk)
+			cut  = min(start+target, hi)
+			best = math.Inf(1)
+		)
+		if hi < lo {
+			// both bounds can't be kept, rest is split in half
+			cut = start + (duration-start)/2
+		}
+
+		for _, s := range scenes {
+			var k = nextKeyframe(keyframes, s)
+			if k < lo || k > hi {
+				continue
+			}
+			if d := math.Abs(k - (start + target)); d < best {
+				cut, best = k, d
+			}
+		}
+
+		if math.IsInf(best, 1) {
+			cut = closestKeyframe(keyframes, cut, lo, hi)
+		}
+		if cut <= start || cut >= duration {
+			// no keyframes till the end, the rest is a single chunk
+			break
+		}
+
+		cuts = append(cuts, cut)
+		start = cut
+	}
+
+	return cuts
+}
+
+// keyframeTolerance is the difference of the same frame time reported by ffmpeg and ffprobe
+const keyframeTolerance = 0.001
+
+// first keyframe at t or after it, t itself if keyframes are unknown,
+// duration is not known here, so +Inf if there are none after t
+func nextKeyframe(keyframes []float64, t float64) float64 {
+	if len(keyframes) == 0 {
+		return t
+	}
+	i, _ := slices.BinarySearch(keyframes, t-keyframeTolerance)
+	if i == len(keyframes) {
+		return math.Inf(1)
 	}
+	return keyframes[i]
+}
 
-	return oneChunkDuration, true
+// keyframe in [lo, hi] closest to t, the first one after t if there are none in range
+func closestKeyframe(keyframes []float64, t, lo, hi float64) float64 {
+	var (
+		res  = nextKeyframe(keyframes, t)
+		best = math.Inf(1)
+	)
+	for _, k := range keyframes {
+		if k < lo || k > hi {
+			continue
+		}
+		if d := math.Abs(k - t); d < best {
+			res, best = k, d
+		}
+	}
+	return res
 }
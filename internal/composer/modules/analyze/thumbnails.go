@@ -0,0 +1,51 @@
+package analyze
+
+import (
+	"math"
+	"slices"
+
+	"github.com/timohahaa/transcoder/pkg/ffmpeg"
+	pb "github.com/timohahaa/transcoder/proto/composer"
+)
+
+// thumbnails are taken at every multiple of interval on the whole video timeline,
+// every chunk produces the ones falling into its time range
+func CalcThumbnails(
+	chunks []ffmpeg.Chunk,
+	chunkPresets map[string]ChunkPresets,
+	interval float64,
+	width int,
+) map[string]*pb.ThumbnailsPreset {
+	// rounding errors of chunk durations
+	const eps = 1e-3
+
+	var (
+		res    = make(map[string]*pb.ThumbnailsPreset, len(chunks))
+		sorted = slices.Clone(chunks)
+		start  float64
+	)
+	slices.SortFunc(sorted, func(a, b ffmpeg.Chunk) int {
+		return a.Num - b.Num
+	})
+
+	for _, chunk := range sorted {
+		var (
+			end   = start + chunkPresets[chunk.Name].Ffprobe.GetDuration()
+			first = math.Ceil((start - eps) / interval)
+			last  = math.Ceil((end-eps)/interval) - 1 // last index strictly before end
+		)
+
+		if last >= first {
+			res[chunk.Name] = &pb.ThumbnailsPreset{
+				Interval: float32(interval),
+				Offset:   float32(max(0, first*interval-start)),
+				Count:    int32(last - first + 1),
+				Width:    int32(width),
+			}
+		}
+
+		start = end
+	}
+
+	return res
+}
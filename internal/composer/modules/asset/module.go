@@ -0,0 +1,139 @@
+package asset
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/minio/minio-go/v7"
+	"github.com/timohahaa/transcoder/internal/composer/modules/task"
+	"github.com/timohahaa/transcoder/pkg/storage"
+)
+
+type Module struct {
+	conn      *pgxpool.Pool
+	outputDir string
+	s3        *minio.Client // nil if s3 is not configured
+}
+
+type Config struct {
+	OutputDir string // destination of tasks without one
+	S3        storage.S3Config
+}
+
+func New(conn *pgxpool.Pool, cfg Config) (*Module, error) {
+	var m = &Module{
+		conn:      conn,
+		outputDir: cfg.OutputDir,
+	}
+
+	if cfg.S3.Endpoint != "" {
+		var err error
+		if m.s3, err = storage.NewS3Client(cfg.S3); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// Storage of the task destination, composer output dir is used if none is set
+func (m *Module) Storage(t task.Task) (storage.Storage, error) {
+	var d = t.Destination
+	switch {
+	case d.S3 != nil:
+		if m.s3 == nil {
+			return nil, fmt.Errorf("s3 destination requested, but s3 is not configured")
+		}
+		return storage.NewS3(m.s3, d.S3.Bucket, d.S3.Prefix), nil
+	case d.HTTP != nil:
+		return storage.NewHTTP(d.HTTP.URL, d.HTTP.Headers), nil
+	case d.FS != nil:
+		// validated on creation, checked again as tasks are read back from db
+		if !filepath.IsLocal(d.FS.Path) {
+			return nil, fmt.Errorf("fs destination %q is outside of the output dir", d.FS.Path)
+		}
+		return storage.NewFS(filepath.Join(m.outputDir, d.FS.Path)), nil
+	default:
+		return storage.NewFS(filepath.Join(m.outputDir, t.ID.String())), nil
+	}
+}
+
+// Save replaces task assets, so retries of assembling leave no stale rows
+func (m *Module) Save(ctx context.Context, taskID uuid.UUID, assets []Asset) (retErr error) {
+	var tx, err = m.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if retErr != nil {
+			_ = tx.Rollback(context.Background())
+		}
+	}()
+
+	if _, err := tx.Exec(ctx, deleteByTaskQuery, taskID); err != nil {
+		return err
+	}
+
+	for _, a := range assets {
+		if _, err := tx.Exec(ctx, createQuery,
+			taskID,
+			a.Path,
+			a.Kind,
+			a.Location,
+			a.Size,
+			a.SHA256,
+			a.Codec,
+			a.BitRate,
+			a.Width,
+			a.Height,
+			a.Duration,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (m *Module) List(ctx context.Context, taskID uuid.UUID) ([]Asset, error) {
+	rows, err := m.conn.Query(ctx, listQuery, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Asset, error) {
+		return scan(row)
+	})
+}
+
+func (m *Module) Get(ctx context.Context, taskID uuid.UUID, path string) (Asset, error) {
+	return scan(m.conn.QueryRow(ctx, getQuery, taskID, path))
+}
+
+func scan(row pgx.Row) (Asset, error) {
+	var (
+		a   Asset
+		err error
+	)
+	err = row.Scan(
+		&a.TaskID,
+		&a.Path,
+		&a.Kind,
+		&a.Location,
+		&a.Size,
+		&a.SHA256,
+		&a.Codec,
+		&a.BitRate,
+		&a.Width,
+		&a.Height,
+		&a.Duration,
+		&a.CreatedAt,
+	)
+	return a, err
+}
@@ -0,0 +1,74 @@
+package asset
+
+const (
+	deleteByTaskQuery = `
+	DELETE FROM transcoder.assets
+	WHERE task_id = $1
+	`
+
+	createQuery = `
+	INSERT INTO transcoder.assets (
+		task_id
+		, path
+		, kind
+		, location
+		, size
+		, sha256
+		, codec
+		, bitrate
+		, width
+		, height
+		, duration
+	) VALUES (
+		$1
+		, $2
+		, $3
+		, $4
+		, $5
+		, $6
+		, $7
+		, $8
+		, $9
+		, $10
+		, $11
+	)
+	`
+
+	listQuery = `
+	SELECT
+		task_id
+		, path
+		, kind
+		, location
+		, size
+		, sha256
+		, codec
+		, bitrate
+		, width
+		, height
+		, duration
+		, created_at
+	FROM transcoder.assets
+	WHERE task_id = $1
+	ORDER BY path
+	`
+
+	getQuery = `
+	SELECT
+		task_id
+		, path
+		, kind
+		, location
+		, size
+		, sha256
+		, codec
+		, bitrate
+		, width
+		, height
+		, duration
+		, created_at
+	FROM transcoder.assets
+	WHERE task_id = $1
+		AND path = $2
+	`
+)
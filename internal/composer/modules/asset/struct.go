@@ -0,0 +1,33 @@
+package asset
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	KindManifest   = "manifest"
+	KindVideo      = "video"
+	KindAudio      = "audio"
+	KindSubtitles  = "subtitles"
+	KindPoster     = "poster"
+	KindThumbnails = "thumbnails"
+	KindOther      = "other"
+)
+
+// published task file, media fields are empty for non media kinds
+type Asset struct {
+	TaskID    uuid.UUID `db:"task_id"    json:"task_id"`
+	Path      string    `db:"path"       json:"path"` // relative to the destination
+	Kind      string    `db:"kind"       json:"kind"      enums:"manifest,video,audio,subtitles,poster,thumbnails,other"`
+	Location  string    `db:"location"   json:"location"` // file path, url or s3:// uri
+	Size      int64     `db:"size"       json:"size"`
+	SHA256    string    `db:"sha256"     json:"sha256"`
+	Codec     string    `db:"codec"      json:"codec,omitempty"`   // RFC 6381 codec string
+	BitRate   int64     `db:"bitrate"    json:"bitrate,omitempty"` // average, bits per second
+	Width     int       `db:"width"      json:"width,omitempty"`
+	Height    int       `db:"height"     json:"height,omitempty"`
+	Duration  float64   `db:"duration"   json:"duration,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,78 @@
+package drm
+
+import (
+	"context"
+	"crypto/rand"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/timohahaa/transcoder/pkg/mp4encrypt"
+)
+
+const (
+	keySize = 16
+	// cenc uses 8-byte per-sample IVs, cbcs uses 16-byte constant IV
+	ivSizeCENC = 8
+	ivSizeCBCS = 16
+)
+
+type Module struct {
+	conn *pgxpool.Pool
+}
+
+func New(conn *pgxpool.Pool) *Module {
+	return &Module{
+		conn: conn,
+	}
+}
+
+// Create generates content key for the task,
+// returns existing one if the task already has a key of the scheme,
+// a retry with another scheme gets a new key, IV sizes of schemes differ
+func (m *Module) Create(ctx context.Context, taskID uuid.UUID, scheme string) (Key, error) {
+	var ivSize = ivSizeCENC
+	if scheme == mp4encrypt.SchemeCBCS {
+		ivSize = ivSizeCBCS
+	}
+
+	var (
+		key   = make([]byte, keySize)
+		iv    = make([]byte, ivSize)
+		k     Key
+		err   error
+		keyID uuid.UUID
+	)
+	if keyID, err = uuid.NewRandom(); err != nil {
+		return Key{}, err
+	}
+	if _, err = rand.Read(key); err != nil {
+		return Key{}, err
+	}
+	if _, err = rand.Read(iv); err != nil {
+		return Key{}, err
+	}
+
+	err = m.conn.QueryRow(ctx, createQuery, taskID, keyID, key, iv, scheme).Scan(
+		&k.TaskID,
+		&k.KeyID,
+		&k.Key,
+		&k.IV,
+		&k.Scheme,
+	)
+	return k, err
+}
+
+func (m *Module) GetByKeyID(ctx context.Context, keyID uuid.UUID) (Key, error) {
+	var (
+		k   Key
+		err error
+	)
+	err = m.conn.QueryRow(ctx, getByKeyIDQuery, keyID).Scan(
+		&k.TaskID,
+		&k.KeyID,
+		&k.Key,
+		&k.IV,
+		&k.Scheme,
+	)
+	return k, err
+}
@@ -0,0 +1,44 @@
+package drm
+
+const (
+	// key is generated once per task, retries of assembling reuse it,
+	// so already delivered manifests stay valid
+	createQuery = `
+	INSERT INTO transcoder.keys (
+		task_id
+		, key_id
+		, key
+		, iv
+		, scheme
+	) VALUES (
+		$1
+		, $2
+		, $3
+		, $4
+		, $5
+	)
+	ON CONFLICT (task_id) DO UPDATE
+	SET
+		key_id = CASE WHEN keys.scheme = EXCLUDED.scheme THEN keys.key_id ELSE EXCLUDED.key_id END
+		, key = CASE WHEN keys.scheme = EXCLUDED.scheme THEN keys.key ELSE EXCLUDED.key END
+		, iv = CASE WHEN keys.scheme = EXCLUDED.scheme THEN keys.iv ELSE EXCLUDED.iv END
+		, scheme = EXCLUDED.scheme
+	RETURNING
+		task_id
+		, key_id
+		, key
+		, iv
+		, scheme
+	`
+
+	getByKeyIDQuery = `
+	SELECT
+		task_id
+		, key_id
+		, key
+		, iv
+		, scheme
+	FROM transcoder.keys
+	WHERE key_id = $1
+	`
+)
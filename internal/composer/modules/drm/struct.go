@@ -0,0 +1,11 @@
+package drm
+
+import "github.com/google/uuid"
+
+type Key struct {
+	TaskID uuid.UUID `db:"task_id"`
+	KeyID  uuid.UUID `db:"key_id"`
+	Key    []byte    `db:"key"`
+	IV     []byte    `db:"iv"`
+	Scheme string    `db:"scheme"` // cenc, cbcs
+}
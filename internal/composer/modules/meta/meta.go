@@ -0,0 +1,83 @@
+// Package meta is a handoff between splitter and assembler,
+// both work in the same task dir on the same host.
+package meta
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	pb "github.com/timohahaa/transcoder/proto/composer"
+)
+
+const fileName = "meta.json"
+
+// Meta describes what the encoders were asked to produce
+type Meta struct {
+	Duration float64 `json:"duration"` // unmuxed video
+	// thumbnails sprite layout, nil if disabled
+	Thumbnails *Thumbnails `json:"thumbnails,omitempty"`
+	Chunks     []Chunk     `json:"chunks"` // ordered by number
+	Audios     []Audio     `json:"audios"` // ordered by track number
+	// converted to WebVTT by the splitter itself
+	Subtitles []Subtitle `json:"subtitles,omitempty"`
+}
+
+type Chunk struct {
+	Name     string       `json:"name"`
+	Num      int          `json:"num"`
+	Duration float64      `json:"duration"`
+	Presets  []*pb.Preset `json:"presets"`
+	// probe encode estimate, 1 is content of average complexity, 0 if not measured
+	Complexity float64 `json:"complexity,omitempty"`
+	// nil if no thumbnails fall into the chunk or they are disabled
+	Thumbnails *pb.ThumbnailsPreset `json:"thumbnails,omitempty"`
+}
+
+type Thumbnails struct {
+	Interval float64 `json:"interval"`
+	Width    int     `json:"width"`
+	Columns  int     `json:"columns"`
+	Rows     int     `json:"rows"`
+}
+
+type Audio struct {
+	TrackNum int             `json:"track_num"`
+	Duration float64         `json:"duration"`
+	Preset   *pb.AudioPreset `json:"preset"`
+
+	Language    string `json:"language,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Default     bool   `json:"default"`
+	Forced      bool   `json:"forced"`
+	StreamIndex int    `json:"stream_index"` // in the source file
+}
+
+type Subtitle struct {
+	Num      int    `json:"num"`
+	File     string `json:"file"`  // relative to the task dir
+	Codec    string `json:"codec"` // of the source stream
+	Language string `json:"language,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Default  bool   `json:"default"`
+	Forced   bool   `json:"forced"`
+}
+
+func Write(taskDir string, m Meta) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(taskDir, fileName), data, 0o644)
+}
+
+func Read(taskDir string) (Meta, error) {
+	var m Meta
+
+	data, err := os.ReadFile(filepath.Join(taskDir, fileName))
+	if err != nil {
+		return m, err
+	}
+
+	return m, json.Unmarshal(data, &m)
+}
@@ -50,8 +50,8 @@ func New(conn *pgxpool.Pool, redis redis.UniversalClient) *Module {
 }
 
 func (m *Module) PrepareTaskMeta(ctx context.Context, taskID uuid.UUID) error {
-	taskCounterKey := key.Counter(taskID)
-	if err := m.redis.Set(ctx, taskCounterKey, "0", 24*time.Hour).Err(); err != nil {
+	taskFinishedKey := key.Finished(taskID)
+	if err := m.redis.Del(ctx, taskFinishedKey).Err(); err != nil {
 		return errors.Redis(err)
 	}
 
@@ -60,6 +60,11 @@ func (m *Module) PrepareTaskMeta(ctx context.Context, taskID uuid.UUID) error {
 		return errors.Redis(err)
 	}
 
+	// scores of a previous split attempt
+	if err := m.redis.Del(ctx, key.Metrics(taskID)).Err(); err != nil {
+		return errors.Redis(err)
+	}
+
 	return nil
 }
 
@@ -76,8 +81,22 @@ func (m *Module) AddSubtask(ctx context.Context, queueKey string, subtask *pb.Ta
 	return nil
 }
 
-func (m *Module) FinishSubtask(ctx context.Context, taskID uuid.UUID) (currCount int64, err error) {
-	return m.redis.Incr(ctx, key.Counter(taskID)).Result()
+// parts are counted once however many times they are finished, so re-issued ones
+// (quality gate retries, redelivered subtasks) don't overflow the task,
+// added is false if the part has already been counted
+func (m *Module) FinishSubtask(ctx context.Context, taskID uuid.UUID, part int32) (currCount int64, added bool, err error) {
+	var (
+		finishedKey = key.Finished(taskID)
+		tx          = m.redis.TxPipeline()
+		addCmd      = tx.SAdd(ctx, finishedKey, part)
+		countCmd    = tx.SCard(ctx, finishedKey)
+	)
+	tx.Expire(ctx, finishedKey, 24*time.Hour)
+
+	if _, err = tx.Exec(ctx); err != nil {
+		return 0, false, err
+	}
+	return countCmd.Val(), addCmd.Val() == 1, nil
 }
 
 func (m *Module) SkipTask(ctx context.Context, taskID uuid.UUID) error {
@@ -6,8 +6,9 @@ func Skip(taskID uuid.UUID) string {
 	return "transcoder:" + taskID.String() + ":skip"
 }
 
-func Counter(taskID uuid.UUID) string {
-	return "transcoder:" + taskID.String() + ":counter"
+// set of finished subtask parts
+func Finished(taskID uuid.UUID) string {
+	return "transcoder:" + taskID.String() + ":finished"
 }
 
 func Progress(taskID uuid.UUID) string {
@@ -17,3 +18,7 @@ func Progress(taskID uuid.UUID) string {
 func EncodingProgress(taskID uuid.UUID) string {
 	return "transcoder:" + ":" + taskID.String() + ":progress:encoding"
 }
+
+func Metrics(taskID uuid.UUID) string {
+	return "transcoder:" + taskID.String() + ":metrics"
+}
@@ -0,0 +1,98 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/timohahaa/transcoder/internal/composer/modules/task/key"
+	pb "github.com/timohahaa/transcoder/proto/composer"
+	"google.golang.org/protobuf/proto"
+)
+
+// SaveChunkMetrics keeps scores of a chunk until the task is assembled,
+// scores of a re-encoded chunk rendition replace previous ones
+func (m *Module) SaveChunkMetrics(ctx context.Context, taskID uuid.UUID, part int32, metrics []*pb.RenditionMetrics) error {
+	var fields = make([]any, 0, 2*len(metrics))
+	for _, r := range metrics {
+		data, err := proto.Marshal(r)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, fmt.Sprintf("%d/%s", part, r.Rendition), data)
+	}
+
+	var (
+		metricsKey = key.Metrics(taskID)
+		tx         = m.redis.TxPipeline()
+	)
+	tx.HSet(ctx, metricsKey, fields...)
+	tx.Expire(ctx, metricsKey, 24*time.Hour)
+
+	_, err := tx.Exec(ctx)
+	return err
+}
+
+// GetMetrics merges scores of all chunks, renditions are sorted by quality
+func (m *Module) GetMetrics(ctx context.Context, taskID uuid.UUID) ([]RenditionMetrics, error) {
+	chunks, err := m.redis.HGetAll(ctx, key.Metrics(taskID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var merged = map[string]*pb.RenditionMetrics{}
+	for _, data := range chunks {
+		var r = &pb.RenditionMetrics{}
+		if err := proto.Unmarshal([]byte(data), r); err != nil {
+			return nil, err
+		}
+
+		var acc, ok = merged[r.Rendition]
+		if !ok {
+			merged[r.Rendition] = r
+			continue
+		}
+		acc.VMAF = acc.VMAF.Merge(r.VMAF)
+		acc.PSNR = acc.PSNR.Merge(r.PSNR)
+		acc.SSIM = acc.SSIM.Merge(r.SSIM)
+	}
+
+	var res = make([]RenditionMetrics, 0, len(merged))
+	for name, r := range merged {
+		res = append(res, RenditionMetrics{
+			Rendition: name,
+			VMAF:      summarize(r.VMAF),
+			PSNR:      summarize(r.PSNR),
+			SSIM:      summarize(r.SSIM),
+		})
+	}
+
+	// 360, 360_av1, 360_hevc, 480, ...
+	slices.SortFunc(res, func(a, b RenditionMetrics) int {
+		aQuality, aCodec, _ := strings.Cut(a.Rendition, "_")
+		bQuality, bCodec, _ := strings.Cut(b.Rendition, "_")
+		aNum, _ := strconv.Atoi(aQuality)
+		bNum, _ := strconv.Atoi(bQuality)
+		if aNum != bNum {
+			return aNum - bNum
+		}
+		return strings.Compare(aCodec, bCodec)
+	})
+
+	return res, nil
+}
+
+func summarize(s *pb.MetricScores) *MetricSummary {
+	if s == nil {
+		return nil
+	}
+	return &MetricSummary{
+		Mean: s.Mean,
+		Min:  s.Min,
+		P5:   s.Percentile(5),
+	}
+}
@@ -41,11 +41,13 @@ func (m *Module) GetForSplitting(hostname string) (Task, error) {
 	err = m.conn.QueryRow(context.Background(), getForSplittingQuery, hostname).Scan(
 		&t.ID,
 		&t.Source,
+		&t.Destination,
 		&t.Encoder,
 		&t.Routing,
 		&t.Duration,
 		&t.FileSize,
 		&t.Settings,
+		&t.Result,
 	)
 	if err != nil {
 		if stdErrors.Is(err, pgx.ErrNoRows) {
@@ -66,11 +68,13 @@ func (m *Module) GetForAssembling(hostname string) (Task, error) {
 	err = m.conn.QueryRow(context.Background(), getForAssemblingQuery, hostname).Scan(
 		&t.ID,
 		&t.Source,
+		&t.Destination,
 		&t.Encoder,
 		&t.Routing,
 		&t.Duration,
 		&t.FileSize,
 		&t.Settings,
+		&t.Result,
 	)
 	if err != nil {
 		if stdErrors.Is(err, pgx.ErrNoRows) {
@@ -115,6 +119,11 @@ func (m *Module) UpdateStatus(ctx context.Context, taskID uuid.UUID, status stri
 	return err
 }
 
+func (m *Module) UpdateResult(ctx context.Context, taskID uuid.UUID, result Result) error {
+	_, err := m.conn.Exec(ctx, updateResultQuery, taskID, result)
+	return err
+}
+
 func (m *Module) UpdateEncodingProgress(ctx context.Context, taskID uuid.UUID, delta int64) error {
 	var (
 		videoDur float64
@@ -164,18 +173,21 @@ func (m *Module) Create(ctx context.Context, form CreateForm) (Task, error) {
 	)
 	err = m.conn.QueryRow(ctx, createQuery,
 		form.Source,
+		form.Destination,
 		form.Duration,
 		form.FileSize,
 		form.Settings,
 	).Scan(
 		&t.ID,
 		&t.Source,
+		&t.Destination,
 		&t.Status,
 		&t.Encoder,
 		&t.Routing,
 		&t.Duration,
 		&t.FileSize,
 		&t.Settings,
+		&t.Result,
 		&t.Error,
 	)
 	return t, err
@@ -189,12 +201,14 @@ func (m *Module) Get(ctx context.Context, id uuid.UUID) (Task, error) {
 	err = m.conn.QueryRow(ctx, getQuery, id).Scan(
 		&t.ID,
 		&t.Source,
+		&t.Destination,
 		&t.Status,
 		&t.Encoder,
 		&t.Routing,
 		&t.Duration,
 		&t.FileSize,
 		&t.Settings,
+		&t.Result,
 		&t.Error,
 	)
 	return t, err
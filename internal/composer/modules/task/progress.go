@@ -11,6 +11,9 @@ const (
 	encodingPercentRange   = ProgressAfterEncoding - progressAfterSplitting
 
 	ProgressAfterStitch        = 87
-	ProgressAfterFragmentVideo = 95
-	ProgressAfterFragmentAudio = 100
+	ProgressAfterFragmentVideo = 91
+	ProgressAfterFragmentAudio = 93
+	ProgressAfterEncrypt       = 95
+	ProgressAfterManifests     = 96
+	ProgressAfterUpload        = 100
 )
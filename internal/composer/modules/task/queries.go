@@ -20,11 +20,13 @@ const (
 	) RETURNING
 		task_id
 		, source
+		, destination
 		, encoder
 		, routing
 		, duration
 		, file_size
 		, settings
+		, result
 	`
 
 	getForAssemblingQuery = `
@@ -45,11 +47,13 @@ const (
 	) RETURNING
 		task_id
 		, source
+		, destination
 		, encoder
 		, routing
 		, duration
 		, file_size
 		, settings
+		, result
 	`
 
 	checkCancellationQuery = `
@@ -72,6 +76,14 @@ const (
 		AND status != 'done'
     `
 
+	updateResultQuery = `
+	UPDATE transcoder.queue
+	SET
+		updated_at = CURRENT_TIMESTAMP
+		, result = $2
+	WHERE task_id = $1
+	`
+
 	getTaskDurationQuery = `
 	SELECT 
 		duration
@@ -83,6 +95,7 @@ const (
 	createQuery = `
 	INSERT INTO transcoder.queue (
 		source
+		, destination
 		, duration
 		, file_size
 		, settings
@@ -91,29 +104,34 @@ const (
 		, $2
 		, $3
 		, $4
-	) 
+		, $5
+	)
 	RETURNING
 		task_id
 		, source
+		, destination
 		, status
 		, encoder
 		, routing
 		, duration
 		, file_size
 		, settings
+		, result
 		, error
 	`
 
 	getQuery = `
-	SELECT 
+	SELECT
 		task_id
 		, source
+		, destination
 		, status
 		, encoder
 		, routing
 		, duration
 		, file_size
 		, settings
+		, result
 		, error
 	FROM transcoder.queue
 	WHERE task_id = $1
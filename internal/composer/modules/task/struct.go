@@ -3,8 +3,10 @@ package task
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/timohahaa/transcoder/pkg/consts"
 	pb "github.com/timohahaa/transcoder/proto/composer"
 )
 
@@ -27,15 +29,17 @@ const (
 )
 
 type Task struct {
-	ID       uuid.UUID `db:"task_id"   json:"id"`
-	Source   Source    `db:"source"    json:"source"`
-	Status   string    `db:"status"    json:"status"`
-	Encoder  string    `db:"encoder"   json:"encoder"`
-	Routing  string    `db:"routing"   json:"routing"`
-	Duration float64   `db:"duration"  json:"duration"`
-	FileSize int64     `db:"file_size" json:"file_size"`
-	Settings Settings  `db:"settings"  json:"settings"`
-	Error    *pb.Error `db:"error"     json:"error"`
+	ID          uuid.UUID   `db:"task_id"     json:"id"`
+	Source      Source      `db:"source"      json:"source"`
+	Destination Destination `db:"destination" json:"destination"`
+	Status      string      `db:"status"      json:"status"`
+	Encoder     string      `db:"encoder"     json:"encoder"`
+	Routing     string      `db:"routing"     json:"routing"`
+	Duration    float64     `db:"duration"    json:"duration"`
+	FileSize    int64       `db:"file_size"   json:"file_size"`
+	Settings    Settings    `db:"settings"    json:"settings"`
+	Result      Result      `db:"result"      json:"result"`
+	Error       *pb.Error   `db:"error"       json:"error"`
 }
 
 type Source struct {
@@ -67,8 +71,384 @@ type SourceFS struct {
 	Path string `json:"path"`
 }
 
+const (
+	PackagingSingleFile = "single-file" // one fragmented mp4 per rendition, byte-range addressing
+	PackagingSegmented  = "segmented"   // init.mp4 + seg_NNNNN.m4s per rendition
+)
+
+const (
+	SplitFixed = "fixed" // 60 second chunks
+	SplitScene = "scene" // cuts at scene changes
+)
+
+const (
+	DeinterlaceFieldRate = "field" // a frame per field, 50i becomes 50p
+	DeinterlaceFrameRate = "frame" // a frame per frame, 50i becomes 25p
+)
+
+const (
+	EncryptionCENC = "cenc"
+	EncryptionCBCS = "cbcs"
+)
+
+// where finished assets are published,
+// composer output dir is used if none is set
+type Destination struct {
+	FS   *DestinationFS   `json:"fs,omitempty"`
+	HTTP *DestinationHTTP `json:"http,omitempty"`
+	S3   *DestinationS3   `json:"s3,omitempty"`
+}
+
+func (d *Destination) Scan(value any) error {
+	var source []byte
+	switch v := value.(type) {
+	case []byte:
+		source = v
+	case string:
+		source = []byte(v)
+	}
+
+	return json.Unmarshal(source, &d)
+}
+
+func (d Destination) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+type DestinationFS struct {
+	Path string `json:"path" validate:"required,local_path"` // relative to the composer output dir
+}
+
+// every asset is uploaded with PUT <url>/<asset path>
+type DestinationHTTP struct {
+	URL     string            `json:"url"               validate:"required,url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// credentials and endpoint are taken from composer config
+type DestinationS3 struct {
+	Bucket string `json:"bucket" validate:"required"`
+	Prefix string `json:"prefix"`
+}
+
+// filled in while the task is processed, complete once it is done
+type Result struct {
+	Posters []Poster `json:"posters,omitempty"`
+	// measured before normalization, only for tracks that are normalized
+	Loudness []AudioLoudness `json:"loudness,omitempty"`
+	// quality metrics mode only, scores of every video rendition against the source
+	Metrics []RenditionMetrics `json:"metrics,omitempty"`
+	// measured on a sample of the source, nil if deinterlacing is disabled or detection failed
+	Interlace *Interlace `json:"interlace,omitempty"`
+	Warnings  []string   `json:"warnings,omitempty"` // non fatal problems, outputs are still published
+}
+
+func (r *Result) Warn(warning string) {
+	r.Warnings = append(r.Warnings, warning)
+}
+
+func (r *Result) Scan(value any) error {
+	var source []byte
+	switch v := value.(type) {
+	case []byte:
+		source = v
+	case string:
+		source = []byte(v)
+	}
+
+	return json.Unmarshal(source, &r)
+}
+
+func (r Result) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+type Poster struct {
+	Path   string `json:"path"` // relative to the destination
+	Format string `json:"format" enums:"jpg,webp"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+type AudioLoudness struct {
+	TrackNum   int     `json:"track_num"`
+	Integrated float64 `json:"integrated"` // LUFS
+	TruePeak   float64 `json:"true_peak"`  // dBTP
+	LRA        float64 `json:"lra"`        // LU
+	Threshold  float64 `json:"threshold"`  // LUFS
+}
+
+type Interlace struct {
+	FieldOrder   string  `json:"field_order" enums:"tff,bff,progressive"` // of the majority of interlaced frames
+	Ratio        float64 `json:"ratio"`                                   // interlaced frames of classified ones, 0..1
+	Deinterlaced bool    `json:"deinterlaced"`
+}
+
+type RenditionMetrics struct {
+	Rendition string         `json:"rendition"`
+	VMAF      *MetricSummary `json:"vmaf,omitempty"` // nil if encoders have no libvmaf
+	PSNR      *MetricSummary `json:"psnr,omitempty"` // luma, dB
+	SSIM      *MetricSummary `json:"ssim,omitempty"` // luma
+}
+
+// of per-frame scores
+type MetricSummary struct {
+	Mean float64 `json:"mean"`
+	Min  float64 `json:"min"`
+	P5   float64 `json:"p5"` // 5th percentile
+}
+
 type Settings struct {
-	Encrypt bool `json:"encrypt"`
+	Encrypt          bool   `json:"encrypt"`
+	EncryptionScheme string `json:"encryption_scheme" validate:"omitempty,oneof=cenc cbcs" enums:"cenc,cbcs"` // cenc by default
+	Packaging        string `json:"packaging" validate:"omitempty,oneof=single-file segmented" enums:"single-file,segmented"`
+	// seek bar preview sprites, disabled if nil
+	Thumbnails *Thumbnails `json:"thumbnails,omitempty"`
+	// audio tracks to keep, all of them if nil
+	Audio *AudioSelection `json:"audio,omitempty"`
+	// EBU R128 normalization of every audio track, disabled if nil
+	Loudness *Loudness `json:"loudness,omitempty"`
+	// renditions of every audio track, single AAC-LC stereo one if empty
+	AudioLadder []AudioRendition `json:"audio_ladder,omitempty" validate:"omitempty,max=8,dive"`
+	// multichannel sources, defaults if nil
+	Surround *Surround `json:"surround,omitempty"`
+	// ladders encoded next to H.264 one, same qualities, H.264 only if empty
+	VideoCodecs []string `json:"video_codecs,omitempty" validate:"omitempty,max=2,unique,dive,oneof=hevc av1" enums:"hevc,av1"`
+	// score every video rendition with VMAF (PSNR/SSIM if encoders have no libvmaf), slows encoding down
+	QualityMetrics bool `json:"quality_metrics"`
+	// chunk renditions scoring below VMAF floor are re-encoded, disabled if nil, implies quality metrics
+	QualityGate *QualityGate `json:"quality_gate,omitempty"`
+	// how the source is cut into chunks encoded in parallel, fixed if nil
+	Split *Split `json:"split,omitempty"`
+	// keyframe cadence, segment and chunk length, 4/4/60 seconds if nil
+	Timing *Timing `json:"timing,omitempty"`
+	// PQ and HLG sources are always tone mapped to SDR BT.709, HDR ladder is added if set
+	HDR *HDR `json:"hdr,omitempty"`
+	// interlaced sources are detected and deinterlaced, defaults if nil
+	Deinterlace *Deinterlace `json:"deinterlace,omitempty"`
+}
+
+// progressive sources are not affected
+type Deinterlace struct {
+	Disabled  bool    `json:"disabled"`                                                             // interlaced sources are encoded as is
+	Filter    string  `json:"filter"    validate:"omitempty,oneof=bwdif yadif" enums:"bwdif,yadif"` // bwdif by default
+	Rate      string  `json:"rate"      validate:"omitempty,oneof=field frame" enums:"field,frame"` // output frame rate policy, field by default
+	Threshold float64 `json:"threshold" validate:"omitempty,gt=0,lte=1"`                            // share of interlaced frames, 0.5 by default
+}
+
+func (d Deinterlace) WithDefaults() Deinterlace {
+	if d.Filter == "" {
+		d.Filter = consts.DeinterlaceBwdif
+	}
+	if d.Rate == "" {
+		d.Rate = DeinterlaceFieldRate
+	}
+	if d.Threshold == 0 {
+		d.Threshold = 0.5
+	}
+	return d
+}
+
+// SDR sources are not affected
+type HDR struct {
+	Passthrough bool   `json:"passthrough"`                                                          // 10-bit ladder keeping HDR next to the tone mapped one
+	Codec       string `json:"codec" validate:"omitempty,oneof=h264 hevc av1" enums:"h264,hevc,av1"` // of HDR ladder, hevc by default
+}
+
+func (h HDR) WithDefaults() HDR {
+	if h.Codec == "" {
+		h.Codec = consts.CodecHEVC
+	}
+	return h
+}
+
+// keyframes of every rendition are placed on the GOP grid of the source timeline,
+// so segment boundaries of all renditions line up wherever the source is cut into chunks
+type Timing struct {
+	GOP        int            `json:"gop"                  validate:"required,gte=1,lte=10"`                                        // seconds
+	Segment    int            `json:"segment"              validate:"required,gte=1,lte=20,multiple_of=GOP,multiple_of=Renditions"` // fragment/segment seconds
+	Chunk      int            `json:"chunk"                validate:"omitempty,gte=10,lte=600,multiple_of=Segment"`                 // seconds, fixed split only, 60 rounded up to segments by default
+	Renditions map[string]int `json:"renditions,omitempty" validate:"omitempty,dive,gte=1,lte=10"`                                  // GOP seconds by rendition name (360, 1080_hevc), task one for the rest
+}
+
+func (t Timing) WithDefaults() Timing {
+	if t.Chunk == 0 {
+		t.Chunk = (60 + t.Segment - 1) / t.Segment * t.Segment
+	}
+	return t
+}
+
+// of the rendition, see ffmpeg.Preset.Name
+func (t Timing) RenditionGOP(name string) int32 {
+	if gop, ok := t.Renditions[name]; ok {
+		return int32(gop)
+	}
+	return int32(t.GOP)
+}
+
+// scene strategy decodes the whole source to find scene changes before it is split,
+// chunks of fixed one are cut at the nearest keyframe and can be of uneven length
+type Split struct {
+	Strategy  string  `json:"strategy"  validate:"omitempty,oneof=fixed scene" enums:"fixed,scene"` // fixed by default
+	MinChunk  float64 `json:"min_chunk" validate:"omitempty,gte=10,lte=300"`                        // seconds, scene only, 30 by default
+	MaxChunk  float64 `json:"max_chunk" validate:"omitempty,gte=10,lte=600"`                        // seconds, scene only, 90 by default
+	Threshold float64 `json:"threshold" validate:"omitempty,gt=0,lt=1"`                             // scene change score, scene only, 0.3 by default
+}
+
+func (s Split) WithDefaults() Split {
+	if s.Strategy == "" {
+		s.Strategy = SplitFixed
+	}
+	if s.MaxChunk == 0 {
+		s.MaxChunk = 90
+	}
+	if s.MinChunk == 0 {
+		s.MinChunk = 30
+	}
+	s.MinChunk = min(s.MinChunk, s.MaxChunk)
+	if s.Threshold == 0 {
+		s.Threshold = 0.3
+	}
+	return s
+}
+
+// every retry raises maxrate and lowers CRF of the failed renditions,
+// a chunk is accepted as is once retries run out
+type QualityGate struct {
+	MinVMAF    float64 `json:"min_vmaf"    validate:"gt=0,lte=100"`
+	MaxRetries int     `json:"max_retries" validate:"omitempty,gte=1,lte=5"` // 2 by default
+}
+
+func (g QualityGate) WithDefaults() QualityGate {
+	if g.MaxRetries == 0 {
+		g.MaxRetries = 2
+	}
+	return g
+}
+
+// 5.1 and wider tracks get a rendition keeping the layout next to the ladder,
+// mono/stereo renditions of any multichannel track are downmixed
+type Surround struct {
+	DownmixOnly bool    `json:"downmix_only"`                                                 // no multichannel rendition
+	Codec       string  `json:"codec"   validate:"omitempty,oneof=aac eac3" enums:"aac,eac3"` // aac by default, eac3 is up to 5.1
+	Bitrate     int64   `json:"bitrate" validate:"omitempty,gte=128,lte=1024"`                // kbit/s, 384 for 5.1 and 512 for 7.1 aac, 640 for eac3 by default
+	Downmix     Downmix `json:"downmix"`
+}
+
+// levels are linear gains of channels mixed into left and right
+type Downmix struct {
+	Center   float64 `json:"center"   validate:"omitempty,gt=0,lte=1"`                            // 0.707 (-3 dB) by default
+	Surround float64 `json:"surround" validate:"omitempty,gt=0,lte=1"`                            // 0.707 (-3 dB) by default
+	LFE      float64 `json:"lfe"      validate:"omitempty,gte=0,lte=1"`                           // not mixed by default
+	Encoding string  `json:"encoding" validate:"omitempty,oneof=dolby dplii" enums:"dolby,dplii"` // for matrix surround decoders, none by default
+}
+
+func (s Surround) WithDefaults() Surround {
+	if s.Codec == "" {
+		s.Codec = consts.AudioCodecAAC
+	}
+	if s.Downmix.Center == 0 {
+		s.Downmix.Center = 0.707
+	}
+	if s.Downmix.Surround == 0 {
+		s.Downmix.Surround = 0.707
+	}
+	return s
+}
+
+type AudioRendition struct {
+	Codec    string `json:"codec"    validate:"required,oneof=aac he-aac opus" enums:"aac,he-aac,opus"`
+	Bitrate  int64  `json:"bitrate"  validate:"required,gte=16,lte=512"` // kbit/s
+	Channels int    `json:"channels" validate:"omitempty,oneof=1 2"`     // 2 by default
+}
+
+// unique within a track, e.g. aac_128k
+func (r AudioRendition) Name() string {
+	return fmt.Sprintf("%s_%dk", r.Codec, r.Bitrate)
+}
+
+// a track is kept if it matches any of languages or streams,
+// empty lists match every track
+type AudioSelection struct {
+	Languages []string `json:"languages,omitempty" validate:"omitempty,dive,gte=2,lte=3"` // ISO 639 as tagged in the source
+	Streams   []int    `json:"streams,omitempty"   validate:"omitempty,dive,gte=0"`       // source stream indexes
+	// default track, the first one with default disposition (or just the first one) if both are empty
+	DefaultLanguage string `json:"default_language,omitempty" validate:"omitempty,gte=2,lte=3,excluded_with=DefaultStream"`
+	DefaultStream   *int   `json:"default_stream,omitempty"   validate:"omitempty,gte=0"`
+}
+
+type Thumbnails struct {
+	Interval float64 `json:"interval" validate:"omitempty,gte=1,lte=60"`   // seconds, 5 by default
+	Width    int     `json:"width"    validate:"omitempty,gte=32,lte=640"` // thumbnail width, 160 by default
+	Columns  int     `json:"columns"  validate:"omitempty,gte=1,lte=30"`   // sprite grid, 10 by default
+	Rows     int     `json:"rows"     validate:"omitempty,gte=1,lte=30"`   // sprite grid, 10 by default
+}
+
+func (t Thumbnails) WithDefaults() Thumbnails {
+	if t.Interval == 0 {
+		t.Interval = 5
+	}
+	if t.Width == 0 {
+		t.Width = 160
+	}
+	// yuv420p needs even dimensions
+	t.Width &^= 1
+	if t.Columns == 0 {
+		t.Columns = 10
+	}
+	if t.Rows == 0 {
+		t.Rows = 10
+	}
+	return t
+}
+
+type Loudness struct {
+	Integrated float64 `json:"integrated" validate:"omitempty,gte=-70,lte=-5"` // LUFS, -23 by default
+	TruePeak   float64 `json:"true_peak"  validate:"omitempty,gte=-9,lte=0"`   // dBTP, -1 by default
+	LRA        float64 `json:"lra"        validate:"omitempty,gte=1,lte=50"`   // LU, 7 by default
+}
+
+func (l Loudness) WithDefaults() Loudness {
+	if l.Integrated == 0 {
+		l.Integrated = -23
+	}
+	if l.TruePeak == 0 {
+		l.TruePeak = -1
+	}
+	if l.LRA == 0 {
+		l.LRA = 7
+	}
+	return l
+}
+
+func (s Settings) Scheme() string {
+	if s.EncryptionScheme == "" {
+		return EncryptionCENC
+	}
+	return s.EncryptionScheme
+}
+
+func (s Settings) Segmented() bool {
+	return s.Packaging == PackagingSegmented
+}
+
+func (s Settings) KeyframeTiming() Timing {
+	if s.Timing == nil {
+		return Timing{GOP: 4, Segment: 4}.WithDefaults()
+	}
+	return s.Timing.WithDefaults()
+}
+
+func (s Settings) Deinterlacing() Deinterlace {
+	if s.Deinterlace == nil {
+		return Deinterlace{}.WithDefaults()
+	}
+	return s.Deinterlace.WithDefaults()
+}
+
+// the gate needs VMAF of every chunk anyway
+func (s Settings) Metrics() bool {
+	return s.QualityMetrics || s.QualityGate != nil
 }
 
 func (s *Settings) Scan(value any) error {
@@ -88,8 +468,9 @@ func (s Settings) Value() (driver.Value, error) {
 }
 
 type CreateForm struct {
-	Source   Source   `db:"source"    json:"source"`
-	Duration float64  `db:"duration"  json:"duration"  validate:"gt=0"`
-	FileSize int64    `db:"file_size" json:"file_size" validate:"gt=0"`
-	Settings Settings `db:"settings"  json:"settings"`
+	Source      Source      `db:"source"      json:"source"`
+	Destination Destination `db:"destination" json:"destination"`
+	Duration    float64     `db:"duration"    json:"duration"  validate:"gt=0"`
+	FileSize    int64       `db:"file_size"   json:"file_size" validate:"gt=0"`
+	Settings    Settings    `db:"settings"    json:"settings"`
 }
@@ -18,7 +18,9 @@ import (
 	"github.com/timohahaa/transcoder/internal/composer/assembler"
 	"github.com/timohahaa/transcoder/internal/composer/handlers/grpc/composer"
 	v1 "github.com/timohahaa/transcoder/internal/composer/handlers/http/v1"
+	"github.com/timohahaa/transcoder/internal/composer/modules/asset"
 	"github.com/timohahaa/transcoder/internal/composer/splitter"
+	"github.com/timohahaa/transcoder/pkg/storage"
 	pb "github.com/timohahaa/transcoder/proto/composer"
 	"google.golang.org/grpc"
 )
@@ -73,11 +75,28 @@ func (srv *Service) Run() error {
 		}
 	)
 
-	mux.Mount("/v1", v1.New(
+	var s3Cfg = storage.S3Config{
+		Endpoint:  srv.cfg.S3.Endpoint,
+		Region:    srv.cfg.S3.Region,
+		AccessKey: srv.cfg.S3.AccessKey,
+		SecretKey: srv.cfg.S3.SecretKey,
+		UseSSL:    srv.cfg.S3.UseSSL,
+	}
+
+	v1Mux, err := v1.New(
 		srv.conn,
 		srv.redis,
 		srv.cfg.WorkDir,
-	))
+		asset.Config{
+			OutputDir: srv.cfg.OutputDir,
+			S3:        s3Cfg,
+		},
+		srv.cfg.KeyServerEnabled,
+	)
+	if err != nil {
+		return err
+	}
+	mux.Mount("/v1", v1Mux)
 
 	log.Infof("HTTP server listening on: %s", srv.cfg.HttpAddr)
 	go func() {
@@ -115,9 +134,15 @@ func (srv *Service) Run() error {
 	})
 	splitter.Run(srv.cfg.Splitter.Workers, srv.cfg.Splitter.Watchers)
 
-	assembler := assembler.New(srv.conn, srv.redis, assembler.Config{
-		WorkDir: srv.cfg.WorkDir,
+	assembler, err := assembler.New(srv.conn, srv.redis, assembler.Config{
+		WorkDir:      srv.cfg.WorkDir,
+		OutputDir:    srv.cfg.OutputDir,
+		KeyServerURL: srv.cfg.KeyServerURL,
+		S3:           s3Cfg,
 	})
+	if err != nil {
+		return err
+	}
 	assembler.Run(srv.cfg.Assembler.Workers, srv.cfg.Assembler.Watchers)
 
 	var signals = []os.Signal{
@@ -0,0 +1,286 @@
+package splitter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/timohahaa/transcoder/internal/composer/modules/analyze"
+	"github.com/timohahaa/transcoder/internal/composer/modules/task"
+	"github.com/timohahaa/transcoder/pkg/consts"
+	"github.com/timohahaa/transcoder/pkg/ffmpeg"
+	"github.com/timohahaa/transcoder/pkg/ffprobe"
+	pb "github.com/timohahaa/transcoder/proto/composer"
+)
+
+// source audio stream to transcode, tags go to manifests
+type audioTrack struct {
+	Stream   ffprobe.Stream
+	Language string // empty if unknown
+	Title    string
+	Default  bool
+	Forced   bool
+}
+
+// selectAudios keeps tracks matching the selection and marks exactly one of them default,
+// problems with the selection are returned as warnings
+func selectAudios(info *ffprobe.Info, sel *task.AudioSelection) ([]audioTrack, []string) {
+	var (
+		tracks   = make([]audioTrack, 0)
+		warnings []string
+	)
+
+	for _, s := range info.GetAllAudios() {
+		var track = audioTrack{
+			Stream:   s,
+			Language: s.Tags.Language,
+			Title:    s.Tags.Title,
+			Forced:   s.Disposition.Forced == 1,
+		}
+		if track.Language == "und" {
+			track.Language = ""
+		}
+
+		if sel != nil && (len(sel.Languages) > 0 || len(sel.Streams) > 0) {
+			var matched = slices.Contains(sel.Streams, s.Index) ||
+				slices.ContainsFunc(sel.Languages, func(lang string) bool {
+					return strings.EqualFold(lang, track.Language)
+				})
+			if !matched {
+				continue
+			}
+		}
+
+		tracks = append(tracks, track)
+	}
+
+	if len(tracks) == 0 {
+		if len(info.GetAllAudios()) > 0 {
+			warnings = append(warnings, "no audio tracks match the selection, output has no audio")
+		}
+		return tracks, warnings
+	}
+
+	var def = -1
+	switch {
+	case sel != nil && sel.DefaultStream != nil:
+		def = slices.IndexFunc(tracks, func(t audioTrack) bool {
+			return t.Stream.Index == *sel.DefaultStream
+		})
+		if def < 0 {
+			warnings = append(warnings, fmt.Sprintf("default audio stream #%d is not selected", *sel.DefaultStream))
+		}
+	case sel != nil && sel.DefaultLanguage != "":
+		def = slices.IndexFunc(tracks, func(t audioTrack) bool {
+			return strings.EqualFold(t.Language, sel.DefaultLanguage)
+		})
+		if def < 0 {
+			warnings = append(warnings, fmt.Sprintf("no selected audio track has default language %q", sel.DefaultLanguage))
+		}
+	}
+	if def < 0 {
+		def = max(0, slices.IndexFunc(tracks, func(t audioTrack) bool {
+			return t.Stream.Disposition.Default == 1
+		}))
+	}
+	tracks[def].Default = true
+
+	return tracks, warnings
+}
+
+func audioStreams(tracks []audioTrack) []ffprobe.Stream {
+	var streams = make([]ffprobe.Stream, 0, len(tracks))
+	for _, t := range tracks {
+		streams = append(streams, t.Stream)
+	}
+	return streams
+}
+
+// measureLoudness sets loudnorm presets for the encoders and reports measured values,
+// tracks that fail to measure (silent ones, etc.) are encoded as is
+func (s *Splitter) measureLoudness(
+	ctx context.Context,
+	t *task.Task,
+	audioFiles []string,
+	audioPresets map[string]analyze.AudioPreset,
+) {
+	var (
+		lg     = s.l.WithFields(log.Fields{"task_id": t.ID})
+		l      = t.Settings.Loudness.WithDefaults()
+		target = ffmpeg.LoudnessTarget{I: l.Integrated, TP: l.TruePeak, LRA: l.LRA}
+	)
+
+	for i, file := range audioFiles {
+		preset, err := analyze.CalcLoudness(ctx, file, target)
+		if err != nil {
+			lg.Warnf("measure loudness of audio %d: %v", i, err)
+			t.Result.Warn(fmt.Sprintf("audio %d is not normalized: %v", i, err))
+			continue
+		}
+
+		audioPresets[file].Preset.Loudness = preset
+		t.Result.Loudness = append(t.Result.Loudness, task.AudioLoudness{
+			TrackNum:   i,
+			Integrated: float64(preset.MeasuredI),
+			TruePeak:   float64(preset.MeasuredTP),
+			LRA:        float64(preset.MeasuredLRA),
+			Threshold:  float64(preset.MeasuredThresh),
+		})
+
+		s.measureDownmixLoudness(ctx, t, i, file, audioPresets[file].Preset, target)
+	}
+}
+
+// downmix gains change loudness, so mono/stereo renditions of multichannel tracks
+// are normalized with values measured on their own downmix
+func (s *Splitter) measureDownmixLoudness(
+	ctx context.Context,
+	t *task.Task,
+	trackNum int,
+	file string,
+	preset *pb.AudioPreset,
+	target ffmpeg.LoudnessTarget,
+) {
+	if preset.Downmix == nil {
+		return
+	}
+
+	ensureDefaultRendition(preset)
+
+	var measured = map[int32]*pb.LoudnessPreset{}
+	for _, r := range preset.Renditions {
+		if r.ChannelLayout != "" {
+			continue
+		}
+
+		l, ok := measured[r.Channels]
+		if !ok {
+			var err error
+			l, err = analyze.CalcDownmixLoudness(ctx, file, *preset.Downmix.Downmix(), int(r.Channels), target)
+			if err != nil {
+				s.l.WithFields(log.Fields{"task_id": t.ID}).Warnf(
+					"measure loudness of audio %d downmix to %d channels: %v", trackNum, r.Channels, err,
+				)
+				t.Result.Warn(fmt.Sprintf(
+					"audio %d downmix to %d channels is not normalized: %v", trackNum, r.Channels, err,
+				))
+			}
+			measured[r.Channels] = l
+		}
+		r.Loudness = l
+	}
+}
+
+// audioLadder converts requested renditions, repeated ones are dropped with a warning
+func audioLadder(ladder []task.AudioRendition) ([]*pb.AudioRendition, []string) {
+	var (
+		res      = make([]*pb.AudioRendition, 0, len(ladder))
+		seen     = make(map[string]bool, len(ladder))
+		warnings []string
+	)
+
+	for _, r := range ladder {
+		var name = r.Name()
+		if seen[name] {
+			warnings = append(warnings, fmt.Sprintf("audio rendition %s is repeated", name))
+			continue
+		}
+		seen[name] = true
+
+		var channels = r.Channels
+		if channels == 0 {
+			channels = 2
+		}
+
+		res = append(res, &pb.AudioRendition{
+			Name:     name,
+			Codec:    r.Codec,
+			Bitrate:  r.Bitrate * consts.KBit,
+			Channels: int32(channels),
+		})
+	}
+
+	return res, warnings
+}
+
+// default bitrates of multichannel renditions
+const (
+	surroundBitrateAAC51 = 384 * consts.KBit
+	surroundBitrateAAC71 = 512 * consts.KBit
+	surroundBitrateEAC3  = 640 * consts.KBit
+)
+
+// setSurround makes downmix of multichannel tracks explicit,
+// 5.1 and wider tracks also get a rendition keeping the layout
+func setSurround(settings *task.Surround, audioPresets map[string]analyze.AudioPreset) {
+	var sur task.Surround
+	if settings != nil {
+		sur = *settings
+	}
+	sur = sur.WithDefaults()
+
+	for _, p := range audioPresets {
+		var streams = p.Ffprobe.GetAllAudios()
+		if len(streams) == 0 || streams[0].Channels <= 2 {
+			continue
+		}
+
+		p.Preset.Downmix = &pb.DownmixPreset{
+			Center:   float32(sur.Downmix.Center),
+			Surround: float32(sur.Downmix.Surround),
+			LFE:      float32(sur.Downmix.LFE),
+			Encoding: sur.Downmix.Encoding,
+		}
+
+		if sur.DownmixOnly || streams[0].Channels < 6 {
+			continue
+		}
+
+		ensureDefaultRendition(p.Preset)
+		analyze.AddAudioRendition(p.Preset, surroundRendition(sur, streams[0].Channels))
+	}
+}
+
+// the default stereo rendition keeps its unnamed file,
+// it is listed once others are added next to it
+func ensureDefaultRendition(p *pb.AudioPreset) {
+	if len(p.Renditions) == 0 {
+		analyze.AddAudioRendition(p, &pb.AudioRendition{
+			Codec:    consts.AudioCodecAAC,
+			Bitrate:  p.Bitrate,
+			Channels: p.Channels,
+		})
+	}
+}
+
+func surroundRendition(sur task.Surround, channels int) *pb.AudioRendition {
+	var (
+		layout         = "5.1"
+		layoutChannels = 6
+		bitrate        = sur.Bitrate * consts.KBit
+	)
+	if channels >= 8 && sur.Codec == consts.AudioCodecAAC {
+		layout, layoutChannels = "7.1", 8
+	}
+
+	if bitrate == 0 {
+		switch {
+		case sur.Codec == consts.AudioCodecEAC3:
+			bitrate = surroundBitrateEAC3
+		case layoutChannels == 8:
+			bitrate = surroundBitrateAAC71
+		default:
+			bitrate = surroundBitrateAAC51
+		}
+	}
+
+	return &pb.AudioRendition{
+		Name:          fmt.Sprintf("%s_%dk_%s", sur.Codec, bitrate/consts.KBit, strings.ReplaceAll(layout, ".", "")),
+		Codec:         sur.Codec,
+		Bitrate:       bitrate,
+		Channels:      int32(layoutChannels),
+		ChannelLayout: layout,
+	}
+}
@@ -0,0 +1,54 @@
+package splitter
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/timohahaa/transcoder/internal/composer/modules/analyze"
+	"github.com/timohahaa/transcoder/internal/composer/modules/task"
+	"github.com/timohahaa/transcoder/pkg/consts"
+	"github.com/timohahaa/transcoder/pkg/ffprobe"
+	pb "github.com/timohahaa/transcoder/proto/composer"
+)
+
+// deinterlace detects interlaced source and records the result,
+// nil is returned for progressive ones, detection failures are not fatal,
+// the source is encoded as is then
+func (s *Splitter) deinterlace(
+	ctx context.Context,
+	t *task.Task,
+	info *ffprobe.Info,
+	videoFile string,
+) *pb.DeinterlacePreset {
+	var settings = t.Settings.Deinterlacing()
+	if settings.Disabled {
+		return nil
+	}
+
+	interlace, err := analyze.DetectInterlace(ctx, videoFile, info)
+	if err != nil {
+		s.l.WithFields(log.Fields{"task_id": t.ID}).Warnf("detect interlace: %v", err)
+		t.Result.Warn("interlace detection failed, video is not deinterlaced: " + err.Error())
+		return nil
+	}
+
+	var (
+		fieldOrder = interlace.FieldOrder()
+		ratio      = interlace.Ratio()
+		interlaced = fieldOrder != consts.FieldOrderProgressive && ratio >= settings.Threshold
+	)
+	t.Result.Interlace = &task.Interlace{
+		FieldOrder:   fieldOrder,
+		Ratio:        ratio,
+		Deinterlaced: interlaced,
+	}
+	if !interlaced {
+		return nil
+	}
+
+	return &pb.DeinterlacePreset{
+		Filter:     settings.Filter,
+		FieldOrder: fieldOrder,
+		FieldRate:  settings.Rate == task.DeinterlaceFieldRate,
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"net/url"
 	"path/filepath"
+	"slices"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/timohahaa/transcoder/internal/composer/modules/analyze"
+	"github.com/timohahaa/transcoder/internal/composer/modules/meta"
 	"github.com/timohahaa/transcoder/internal/composer/modules/task"
 	"github.com/timohahaa/transcoder/pkg/errors"
 	"github.com/timohahaa/transcoder/pkg/ffmpeg"
@@ -93,16 +95,39 @@ func (s *Splitter) process(t task.Task) (task.Task, error) {
 		return t, errors.Unmux(err)
 	}
 
+	audioTracks, warnings := selectAudios(sourceInfo, t.Settings.Audio)
+	for _, warning := range warnings {
+		t.Result.Warn(warning)
+	}
+
 	if audioFiles, err = ffmpeg.UnmuxAudios(
 		ctx,
 		sourceInfo,
 		sourcePath,
 		filepath.Join(taskDir, "audios"),
+		audioStreams(audioTracks),
 	); err != nil && err != ffmpeg.ErrNoAudios {
 		cleanFull = true
 		return t, errors.Unmux(err)
 	}
 
+	// subtitles are optional, a task is not failed because of them
+	var subtitleFiles []string
+	if subtitleFiles, err = ffmpeg.ExtractSubtitles(
+		ctx,
+		sourceInfo,
+		sourcePath,
+		filepath.Join(taskDir, "subtitles"),
+	); err != nil && err != ffmpeg.ErrNoSubtitles {
+		lg.Warnf("extract subtitles: %v", err)
+		t.Result.Warn("subtitles are skipped: " + err.Error())
+		subtitleFiles = nil
+	}
+	for _, warning := range unsupportedSubtitles(sourceInfo) {
+		t.Result.Warn(warning)
+	}
+	var subtitles = buildSubtitles(sourceInfo, taskDir, subtitleFiles)
+
 	progress(task.ProgressAfterUnmux)
 
 	// Need to update ffprobe info after unmux.
@@ -121,7 +146,7 @@ func (s *Splitter) process(t task.Task) (task.Task, error) {
 	}
 
 	var chunks []ffmpeg.Chunk
-	if chunks, err = s.split(ctx, sourceInfo, videoFile, filepath.Join(taskDir, "chunks")); err != nil {
+	if chunks, err = s.split(ctx, &t, sourceInfo, videoFile, filepath.Join(taskDir, "chunks")); err != nil {
 		cleanFull = true
 		return t, err
 	}
@@ -129,11 +154,28 @@ func (s *Splitter) process(t task.Task) (task.Task, error) {
 	progress(task.ProgressAfterSplit)
 
 	// presets
+	var ladder = analyze.LadderOpts{
+		Codecs: t.Settings.VideoCodecs,
+		GOP:    t.Settings.KeyframeTiming().RenditionGOP,
+	}
+	ladder.Deinterlace = s.deinterlace(ctx, &t, sourceInfo, videoFile)
+	if ladder.HDR, err = analyze.DetectHDR(ctx, videoFile, sourceInfo); err != nil {
+		cleanFull = true
+		return t, errors.Splitter(err)
+	}
+	if ladder.HDR != nil && t.Settings.HDR != nil && t.Settings.HDR.Passthrough {
+		ladder.HDRCodec = t.Settings.HDR.WithDefaults().Codec
+	}
+
 	var chunkPresets map[string]analyze.ChunkPresets
-	if chunkPresets, err = analyze.CalcChunkPresets(sourceInfo, chunks); err != nil {
+	if chunkPresets, warnings, err = analyze.CalcChunkPresets(ctx, sourceInfo, chunks, ladder); err != nil {
 		cleanFull = true
 		return t, errors.Splitter(err)
 	}
+	for _, warning := range warnings {
+		lg.Warn(warning)
+		t.Result.Warn(warning)
+	}
 
 	if err := validateChunks(chunkPresets, sourceInfo); err != nil {
 		cleanFull = true
@@ -146,6 +188,41 @@ func (s *Splitter) process(t task.Task) (task.Task, error) {
 		return t, errors.Splitter(err)
 	}
 
+	if len(t.Settings.AudioLadder) > 0 {
+		ladder, warnings := audioLadder(t.Settings.AudioLadder)
+		for _, warning := range warnings {
+			t.Result.Warn(warning)
+		}
+		analyze.SetAudioRenditions(audioPresets, ladder)
+	}
+	setSurround(t.Settings.Surround, audioPresets)
+
+	if t.Settings.Loudness != nil {
+		s.measureLoudness(ctx, &t, audioFiles, audioPresets)
+	}
+
+	var chunkThumbnails map[string]*pb.ThumbnailsPreset
+	if t.Settings.Thumbnails != nil {
+		var th = t.Settings.Thumbnails.WithDefaults()
+		chunkThumbnails = analyze.CalcThumbnails(chunks, chunkPresets, th.Interval, th.Width)
+	}
+
+	// what the encoders are asked for, assembler validates results against it
+	if err := meta.Write(taskDir, buildMeta(
+		t.Settings,
+		sourceInfo,
+		chunks,
+		chunkPresets,
+		chunkThumbnails,
+		audioFiles,
+		audioTracks,
+		audioPresets,
+		subtitles,
+	)); err != nil {
+		cleanFull = true
+		return t, errors.Splitter(err)
+	}
+
 	// write subtasks to redis queue
 	if err := s.writeToRedis(
 		ctx,
@@ -153,7 +230,9 @@ func (s *Splitter) process(t task.Task) (task.Task, error) {
 		sourceInfo,
 		chunks,
 		chunkPresets,
+		chunkThumbnails,
 		audioFiles,
+		audioTracks,
 		audioPresets,
 	); err != nil {
 		cleanFull = true
@@ -164,6 +243,9 @@ func (s *Splitter) process(t task.Task) (task.Task, error) {
 	progress(task.ProgressAfterCreateSubtasks)
 
 	// update db
+	if err := s.mod.task.UpdateResult(ctx, t.ID, t.Result); err != nil {
+		return t, errors.DB(err)
+	}
 	if err := s.mod.task.UpdateStatus(ctx, t.ID, task.StatusEncoding, nil); err != nil {
 		return t, errors.DB(err)
 	}
@@ -171,13 +253,78 @@ func (s *Splitter) process(t task.Task) (task.Task, error) {
 	return t, nil
 }
 
+func buildMeta(
+	settings task.Settings,
+	info *ffprobe.Info,
+	chunks []ffmpeg.Chunk,
+	chunkPresets map[string]analyze.ChunkPresets,
+	chunkThumbnails map[string]*pb.ThumbnailsPreset,
+	audioFiles []string,
+	audioTracks []audioTrack,
+	audioPresets map[string]analyze.AudioPreset,
+	subtitles []meta.Subtitle,
+) meta.Meta {
+	var m = meta.Meta{
+		Duration:  info.GetDuration(),
+		Chunks:    make([]meta.Chunk, 0, len(chunks)),
+		Audios:    make([]meta.Audio, 0, len(audioFiles)),
+		Subtitles: subtitles,
+	}
+
+	if settings.Thumbnails != nil {
+		var th = settings.Thumbnails.WithDefaults()
+		m.Thumbnails = &meta.Thumbnails{
+			Interval: th.Interval,
+			Width:    th.Width,
+			Columns:  th.Columns,
+			Rows:     th.Rows,
+		}
+	}
+
+	for _, chunk := range chunks {
+		var chunkInfo = chunkPresets[chunk.Name]
+		m.Chunks = append(m.Chunks, meta.Chunk{
+			Name:     chunk.Name,
+			Num:      chunk.Num,
+			Duration: chunkInfo.Ffprobe.GetDuration(),
+			Presets:  chunkInfo.Presets,
+
+			Complexity: chunkInfo.Complexity,
+			Thumbnails: chunkThumbnails[chunk.Name],
+		})
+	}
+	slices.SortFunc(m.Chunks, func(a, b meta.Chunk) int {
+		return a.Num - b.Num
+	})
+
+	for i, filePath := range audioFiles {
+		var audioPreset = audioPresets[filePath]
+		var track = audioTracks[i]
+		m.Audios = append(m.Audios, meta.Audio{
+			TrackNum: i,
+			Duration: audioPreset.Ffprobe.GetDuration(),
+			Preset:   audioPreset.Preset,
+
+			Language:    track.Language,
+			Title:       track.Title,
+			Default:     track.Default,
+			Forced:      track.Forced,
+			StreamIndex: track.Stream.Index,
+		})
+	}
+
+	return m
+}
+
 func (s *Splitter) writeToRedis(
 	ctx context.Context,
 	t task.Task,
 	info *ffprobe.Info,
 	chunks []ffmpeg.Chunk,
 	chunkPresets map[string]analyze.ChunkPresets,
+	chunkThumbnails map[string]*pb.ThumbnailsPreset,
 	audioFiles []string,
+	audioTracks []audioTrack,
 	audioPresets map[string]analyze.AudioPreset,
 ) error {
 	var lg = s.l.WithFields(log.Fields{"task_id": t.ID})
@@ -195,6 +342,7 @@ func (s *Splitter) writeToRedis(
 			Quality: high.GetQuality(),
 			Presets: nil,
 			PixFmt:  high.PixFmt,
+			Metrics: t.Settings.Metrics(),
 		}
 		tPb = pb.Task{
 			ID: t.ID[:],
@@ -209,6 +357,13 @@ func (s *Splitter) writeToRedis(
 		queueKey                   = t.Routing
 		baseChunkUrl, baseAudioUrl string
 	)
+	if t.Settings.QualityGate != nil {
+		var gate = t.Settings.QualityGate.WithDefaults()
+		vPb.Gate = &pb.QualityGate{
+			MinVMAF:    float32(gate.MinVMAF),
+			MaxRetries: int32(gate.MaxRetries),
+		}
+	}
 	{
 		parsedUrl, err := url.Parse("http://" + s.cfg.HttpAddr + "/v1/files/chunk")
 		if err != nil {
@@ -232,6 +387,7 @@ func (s *Splitter) writeToRedis(
 
 	lg.Debugf("adding subtasks to queue: %v", queueKey)
 
+	var chunkStart float64
 	for i, chunk := range chunks {
 		chunkSource := baseChunkUrl + "&filepath=" + chunk.Path
 		chunkInfo := chunkPresets[chunk.Name]
@@ -242,6 +398,10 @@ func (s *Splitter) writeToRedis(
 		tPb.Video.BitRate = chunkInfo.Ffprobe.Format.BitRate
 		tPb.Video.Duration = float32(chunkInfo.Ffprobe.GetDuration())
 		tPb.Video.CreatePoster = chunk.Num == 0
+		tPb.Video.Thumbnails = chunkThumbnails[chunk.Name]
+		tPb.Video.Start = chunkStart
+
+		chunkStart += chunkInfo.Ffprobe.GetDuration()
 
 		if err := s.mod.queue.AddSubtask(ctx, queueKey, &tPb); err != nil {
 			return err
@@ -261,6 +421,12 @@ func (s *Splitter) writeToRedis(
 			Duration: float32(audioPreset.Ffprobe.GetDuration()),
 			TrackNum: int32(i),
 			Preset:   audioPreset.Preset,
+
+			Language:    audioTracks[i].Language,
+			Title:       audioTracks[i].Title,
+			Default:     audioTracks[i].Default,
+			Forced:      audioTracks[i].Forced,
+			StreamIndex: int32(audioTracks[i].Stream.Index),
 		}
 
 		if err := s.mod.queue.AddSubtask(ctx, queueKey, &tPb); err != nil {
@@ -7,7 +7,9 @@ import (
 	"os"
 	"path/filepath"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/timohahaa/transcoder/internal/composer/modules/analyze"
+	"github.com/timohahaa/transcoder/internal/composer/modules/task"
 	"github.com/timohahaa/transcoder/pkg/errors"
 	"github.com/timohahaa/transcoder/pkg/ffmpeg"
 	"github.com/timohahaa/transcoder/pkg/ffprobe"
@@ -15,12 +17,36 @@ import (
 
 func (s *Splitter) split(
 	ctx context.Context,
+	t *task.Task,
 	info *ffprobe.Info,
 	srcFile, dstDir string,
 ) ([]ffmpeg.Chunk, error) {
 	var (
-		chunkDuration, needSplit = analyze.CalcChunkSize(info)
-		chunkContainerFormat     = info.GetFileExt()
+		settings             = s.splitSettings(t)
+		chunkContainerFormat = info.GetFileExt()
+	)
+
+	if settings.Strategy == task.SplitScene {
+		cuts, err := s.sceneCuts(ctx, info, srcFile, filepath.Join(filepath.Dir(dstDir), "scenes.log"), settings)
+		switch {
+		case err != nil:
+			// chunks of fixed length are still fine
+			s.l.WithFields(log.Fields{"task_id": t.ID}).Warnf("detect scenes: %v", err)
+			t.Result.Warn("scene detection failed, source is split in fixed chunks: " + err.Error())
+		case len(cuts) == 0:
+			return s.copyChunk(srcFile, dstDir, chunkContainerFormat)
+		default:
+			chunks, err := ffmpeg.SplitAt(ctx, srcFile, dstDir, cuts, chunkContainerFormat)
+			if err != nil {
+				return nil, errors.SplitSources(err)
+			}
+			return chunks, nil
+		}
+	}
+
+	var (
+		timing                   = t.Settings.KeyframeTiming()
+		chunkDuration, needSplit = analyze.CalcChunkSize(info, timing.Chunk, timing.GOP)
 	)
 
 	if needSplit {
@@ -37,6 +63,43 @@ func (s *Splitter) split(
 		return chunks, nil
 	}
 
+	return s.copyChunk(srcFile, dstDir, chunkContainerFormat)
+}
+
+func (s *Splitter) splitSettings(t *task.Task) task.Split {
+	if t.Settings.Split == nil {
+		return task.Split{}.WithDefaults()
+	}
+	return t.Settings.Split.WithDefaults()
+}
+
+// cut times of the source, none if it fits a single chunk
+func (s *Splitter) sceneCuts(
+	ctx context.Context,
+	info *ffprobe.Info,
+	srcFile, logPath string,
+	settings task.Split,
+) ([]float64, error) {
+	var duration = info.GetDuration()
+	if duration <= settings.MaxChunk {
+		return nil, nil
+	}
+
+	scenes, err := ffmpeg.DetectScenes(ctx, srcFile, logPath, settings.Threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	// stream copy cuts at keyframes, scene changes are moved to them
+	keyframes, err := ffprobe.Keyframes(ctx, srcFile)
+	if err != nil {
+		return nil, fmt.Errorf("probe keyframes: %w", err)
+	}
+
+	return analyze.CalcSceneCuts(scenes, keyframes, duration, settings.MinChunk, settings.MaxChunk), nil
+}
+
+func (s *Splitter) copyChunk(srcFile, dstDir, chunkContainerFormat string) ([]ffmpeg.Chunk, error) {
 	// just copy file as first chunk
 	if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
 		return nil, errors.Splitter(err)
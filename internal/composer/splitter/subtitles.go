@@ -0,0 +1,64 @@
+package splitter
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/timohahaa/transcoder/internal/composer/modules/meta"
+	"github.com/timohahaa/transcoder/pkg/ffprobe"
+)
+
+// files are in the order of info.GetTextSubtitles()
+func buildSubtitles(info *ffprobe.Info, taskDir string, files []string) []meta.Subtitle {
+	var (
+		streams   = info.GetTextSubtitles()
+		subtitles = make([]meta.Subtitle, 0, len(files))
+	)
+	for i, file := range files {
+		var stream = streams[i]
+
+		rel, err := filepath.Rel(taskDir, file)
+		if err != nil {
+			rel = file
+		}
+
+		var language = stream.Tags.Language
+		if language == "und" {
+			language = ""
+		}
+
+		subtitles = append(subtitles, meta.Subtitle{
+			Num:      i,
+			File:     rel,
+			Codec:    stream.CodecName,
+			Language: language,
+			Title:    stream.Tags.Title,
+			Default:  stream.Disposition.Default == 1,
+			Forced:   stream.Disposition.Forced == 1,
+		})
+	}
+	return subtitles
+}
+
+// bitmap subtitles (PGS, DVB, DVD) need OCR to become WebVTT
+func unsupportedSubtitles(info *ffprobe.Info) []string {
+	var warnings []string
+	for _, s := range info.GetAllSubtitles() {
+		if s.IsTextSubtitle() {
+			continue
+		}
+
+		var desc = s.CodecName
+		if s.Tags.Language != "" {
+			desc += ", " + s.Tags.Language
+		}
+		if s.Tags.Title != "" {
+			desc += ", " + s.Tags.Title
+		}
+		warnings = append(warnings, fmt.Sprintf(
+			"subtitle stream #%d (%s) is skipped: bitmap subtitles are not supported",
+			s.Index, desc,
+		))
+	}
+	return warnings
+}
@@ -23,6 +23,7 @@ type (
 		cfg           Config
 		signal        chan os.Signal
 		ffmpegVersion string
+		libvmaf       bool
 		composer      *composer.Client
 		workers       []*worker.Worker
 		backlog       chan task
@@ -31,6 +32,9 @@ type (
 	task struct {
 		t  *pb.Task
 		id uuid.UUID
+		// t.Source is the prefetched file while the task is handled,
+		// composer gets the remote one back so the task can be re-issued
+		source string
 	}
 )
 
@@ -49,6 +53,10 @@ func New(cfg Config) (*Service, error) {
 		return nil, err
 	}
 
+	if s.libvmaf, err = ffmpeg.HasFilter("libvmaf"); err != nil {
+		return nil, err
+	}
+
 	if s.composer, err = composer.NewClient(cfg.ComposerAddrs); err != nil {
 		return nil, err
 	}
@@ -81,6 +89,7 @@ func (srv *Service) Run() error {
 			CpuIdx:   i,
 			MaxTasks: 1,
 			WorkDir:  srv.cfg.WorkDir,
+			VMAF:     srv.libvmaf,
 		})
 
 		srv.workers = append(srv.workers, w)
@@ -51,28 +51,34 @@ func (srv *Service) watch(idx int) {
 
 		taskID, err := uuid.FromBytes(t.ID)
 		if err != nil {
-			srv.finishTask(t, taskID, err)
+			srv.finishTask(t, taskID, nil, err)
 			continue
 		}
 
+		var source = t.Source
 		if t.Source, err = srv.prefetch(t, taskID); err != nil {
 			l.WithFields(log.Fields{
 				"task_id": taskID,
 			}).Errorf("prefetch: %s", err)
-			srv.finishTask(t, taskID, err)
+			t.Source = source
+			srv.finishTask(t, taskID, nil, err)
 			continue
 		}
 
 		srv.backlog <- task{
-			t:  t,
-			id: taskID,
+			t:      t,
+			id:     taskID,
+			source: source,
 		}
 	}
 }
 
 func (srv *Service) schedule() {
 	for task := range srv.backlog {
-		finish := func(err error) { srv.finishTask(task.t, task.id, err) }
+		finish := func(metrics []*pb.RenditionMetrics, err error) {
+			task.t.Source = task.source
+			srv.finishTask(task.t, task.id, metrics, err)
+		}
 
 	LOOP:
 		for {
@@ -92,7 +98,7 @@ func (srv *Service) schedule() {
 	}
 }
 
-func (srv *Service) finishTask(task *pb.Task, taskID uuid.UUID, err error) {
+func (srv *Service) finishTask(task *pb.Task, taskID uuid.UUID, metrics []*pb.RenditionMetrics, err error) {
 	var tErr *pb.Error
 	if err != nil {
 		switch e := err.(type) {
@@ -107,6 +113,7 @@ func (srv *Service) finishTask(task *pb.Task, taskID uuid.UUID, err error) {
 		Task:       task,
 		Error:      tErr,
 		FinishedAt: timestamppb.Now(),
+		Metrics:    metrics,
 	}); err != nil {
 		log.WithFields(log.Fields{
 			"task_id": taskID,
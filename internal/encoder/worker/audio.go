@@ -34,21 +34,25 @@ func (w *Worker) audio(task *pb.Task, taskID uuid.UUID) error {
 	}()
 
 	var (
-		err       error
-		audioPath string
+		err        error
+		renditions []ffmpeg.Quality
+		preset     = task.Audio.Preset.Preset()
 	)
 
-	audioPath, err = ffmpeg.EncodeAudio(
+	preset.Language = task.Audio.Language
+	preset.Title = task.Audio.Title
+
+	renditions, err = ffmpeg.EncodeAudio(
 		context.Background(),
 		[]int{w.opts.CpuIdx},
 		task.Source,
 		assetsFolder,
-		task.Audio.Preset.Preset(),
+		preset,
 	)
 
 	if err != nil {
 		return errors.Ffmpeg(err)
 	}
 
-	return w.uploadAudio(task, taskID, audioPath)
+	return w.uploadAudio(task, taskID, renditions)
 }
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/timohahaa/transcoder/pkg/ffmpeg"
+	pb "github.com/timohahaa/transcoder/proto/composer"
+)
+
+// histogram bin widths of per-frame scores
+const (
+	vmafStep = 0.1
+	psnrStep = 0.05
+	ssimStep = 0.0001
+)
+
+// scores every encoded rendition against the source chunk at the top rendition resolution,
+// so scores of different renditions (and of retries) are comparable
+func (w *Worker) metrics(task *pb.Task, logDir string, out *ffmpeg.Output) ([]*pb.RenditionMetrics, error) {
+	var (
+		top     ffmpeg.Preset
+		presets = map[string]ffmpeg.Preset{}
+	)
+	for _, p := range task.Presets() {
+		if p.Width*p.Height > top.Width*top.Height {
+			top = p
+		}
+		presets[p.Name()] = p
+	}
+
+	// encoder swaps preset dimensions for vertical videos
+	var width, height = top.Width, top.Height
+	if top.IsVertical {
+		width, height = height, width
+	}
+
+	var res = make([]*pb.RenditionMetrics, 0, len(out.Qualities))
+	for _, q := range out.Qualities {
+		scores, err := ffmpeg.MeasureQuality(
+			context.Background(),
+			[]int{w.opts.CpuIdx},
+			q.Path,
+			task.Source,
+			ffmpeg.QualityOpts{
+				Width:  width,
+				Height: height,
+				FPS:    presets[q.Name].FPS, // low rungs may have half of the top one
+				VMAF:   w.opts.VMAF,
+				LogDir: logDir,
+				// SDR renditions of HDR source are scored against the tone mapped one
+				Tonemap:          presets[q.Name].Tonemap,
+				SourceColorSpace: presets[q.Name].SourceColorSpace,
+				SourceColorRange: presets[q.Name].SourceColorRange,
+				ColorSpace:       presets[q.Name].ColorSpace,
+				Deinterlace:      presets[q.Name].Deinterlace,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, &pb.RenditionMetrics{
+			Rendition: q.Name,
+			VMAF:      pb.NewMetricScores(scores.VMAF, vmafStep),
+			PSNR:      pb.NewMetricScores(scores.PSNR, psnrStep),
+			SSIM:      pb.NewMetricScores(scores.SSIM, ssimStep),
+		})
+	}
+
+	return res, nil
+}
@@ -14,18 +14,20 @@ func (w *Worker) poster(task *pb.Task, dstDir string, out *ffmpeg.Output) (strin
 		return "", nil
 	}
 
-	slices.SortFunc(out.Qualities, func(a, b ffmpeg.Quality) int {
-		aNum, _ := strconv.Atoi(a.Name)
-		bNum, _ := strconv.Atoi(b.Name)
-		return aNum - bNum
-	})
-
-	var posterQuality = out.Qualities[len(out.Qualities)-1]
-
 	return ffmpeg.PosterThumbCPU(
 		context.Background(),
 		[]int{w.opts.CpuIdx},
-		posterQuality.Path,
+		displayQuality(out).Path,
 		dstDir,
 	)
 }
+
+// displayQuality is the highest H.264 rendition, every player decodes it,
+// renditions of other codecs (1080_hevc) are not numbers and are skipped
+func displayQuality(out *ffmpeg.Output) ffmpeg.Quality {
+	return slices.MaxFunc(out.Qualities, func(a, b ffmpeg.Quality) int {
+		aNum, _ := strconv.Atoi(a.Name)
+		bNum, _ := strconv.Atoi(b.Name)
+		return aNum - bNum
+	})
+}
@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/timohahaa/transcoder/pkg/ffmpeg"
+	pb "github.com/timohahaa/transcoder/proto/composer"
+)
+
+func (w *Worker) thumbnails(task *pb.Task, dstDir string, out *ffmpeg.Output) (string, error) {
+	if len(out.Qualities) == 0 {
+		return "", nil
+	}
+
+	var preset = task.Video.Thumbnails
+
+	return ffmpeg.ThumbnailStripCPU(
+		context.Background(),
+		[]int{w.opts.CpuIdx},
+		displayQuality(out).Path,
+		dstDir,
+		float64(preset.Offset),
+		float64(preset.Interval),
+		int(preset.Count),
+		int(preset.Width),
+	)
+}
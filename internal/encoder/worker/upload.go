@@ -82,22 +82,22 @@ func (w *Worker) uploadPoster(task *pb.Task, taskID uuid.UUID, posterPath string
 	return nil
 }
 
-func (w *Worker) uploadAudio(task *pb.Task, taskID uuid.UUID, audioPath string) error {
+func (w *Worker) uploadThumbnails(task *pb.Task, taskID uuid.UUID, stripPath string) error {
 	var baseURL string
 	{
-		u, err := url.Parse("http://" + task.PushTo + "/v1/files/audio")
+		u, err := url.Parse("http://" + task.PushTo + "/v1/files/thumbnails")
 		if err != nil {
 			return errors.Generic(err)
 		}
 
 		q := url.Values{}
 		q.Add("task_id", taskID.String())
-		q.Add("track_num", strconv.FormatInt(int64(task.Audio.TrackNum), 10))
+		q.Add("chunk_num", strconv.FormatInt(int64(task.Part), 10))
 		u.RawQuery = q.Encode()
 		baseURL = u.String()
 	}
 
-	var resp, err = request.Upload(context.Background(), baseURL, audioPath, retryAttempts)
+	var resp, err = request.Upload(context.Background(), baseURL, stripPath, retryAttempts)
 	if err != nil {
 		return errors.Network(fmt.Errorf("sending request (url = %v): %v", baseURL, err))
 	}
@@ -107,3 +107,40 @@ func (w *Worker) uploadAudio(task *pb.Task, taskID uuid.UUID, audioPath string)
 
 	return nil
 }
+
+func (w *Worker) uploadAudio(task *pb.Task, taskID uuid.UUID, renditions []ffmpeg.Quality) error {
+	var baseURL string
+	{
+		u, err := url.Parse("http://" + task.PushTo + "/v1/files/audio")
+		if err != nil {
+			return errors.Generic(err)
+		}
+
+		q := url.Values{}
+		q.Add("task_id", taskID.String())
+		q.Add("track_num", strconv.FormatInt(int64(task.Audio.TrackNum), 10))
+		u.RawQuery = q.Encode()
+		baseURL = u.String()
+	}
+
+	var eGroup = &errgroup.Group{}
+	for _, r := range renditions {
+		eGroup.Go(func() error {
+			var url = baseURL
+			if r.Name != "" {
+				url += "&rendition=" + r.Name
+			}
+
+			resp, err := request.Upload(context.Background(), url, r.Path, retryAttempts)
+			if err != nil {
+				return errors.Network(fmt.Errorf("sending request (url = %v): %v", url, err))
+			}
+			if resp.StatusCode != http.StatusOK {
+				return errors.Network(fmt.Errorf("expected 200 OK (url = %v): %v", url, resp.StatusCode))
+			}
+			return nil
+		})
+	}
+
+	return eGroup.Wait()
+}
@@ -19,7 +19,7 @@ var (
 	zeroTime, _ = time.Parse(time.TimeOnly, "00:00:00")
 )
 
-func (w *Worker) video(task *pb.Task, taskID uuid.UUID) error {
+func (w *Worker) video(task *pb.Task, taskID uuid.UUID) ([]*pb.RenditionMetrics, error) {
 	var (
 		lg           = w.l.WithFields(log.Fields{"task_id": taskID})
 		assetsFolder = filepath.Join(
@@ -44,6 +44,8 @@ func (w *Worker) video(task *pb.Task, taskID uuid.UUID) error {
 		err        error
 		progCB     = w.getProgressCallback(task, taskID)
 		posterPath string
+		stripPath  string
+		metrics    []*pb.RenditionMetrics
 	)
 
 	out, err = ffmpeg.EncodeCPU(
@@ -51,11 +53,19 @@ func (w *Worker) video(task *pb.Task, taskID uuid.UUID) error {
 		[]int{w.opts.CpuIdx},
 		task.Source,
 		assetsFolder,
-		task.Presets(),
+		task.EncodePresets(),
 		progCB,
 	)
 	if err != nil {
-		return errors.Ffmpeg(err)
+		return nil, errors.Ffmpeg(err)
+	}
+
+	// optional, chunk is not failed because of them
+	if task.Video.Metrics {
+		if metrics, err = w.metrics(task, filepath.Join(assetsFolder, "metrics"), out); err != nil {
+			lg.Errorf("quality metrics: %v", err)
+			metrics = nil
+		}
 	}
 
 	if task.Video.CreatePoster {
@@ -65,17 +75,31 @@ func (w *Worker) video(task *pb.Task, taskID uuid.UUID) error {
 		}
 	}
 
+	if task.Video.Thumbnails != nil {
+		if stripPath, err = w.thumbnails(task, assetsFolder, out); err != nil {
+			lg.Errorf("create thumbnails: %v", err)
+			stripPath = ""
+		}
+	}
+
 	if err := w.uploadChunks(task, taskID, out.Qualities); err != nil {
-		return err
+		return nil, err
 	}
 
 	if _, err = os.Stat(posterPath); err == nil {
 		if err := w.uploadPoster(task, taskID, posterPath); err != nil {
-			return err
+			return nil, err
+		}
+	}
+
+	// assembler leaves blank tiles for missing strips
+	if _, err = os.Stat(stripPath); err == nil {
+		if err := w.uploadThumbnails(task, taskID, stripPath); err != nil {
+			lg.Errorf("upload thumbnails: %v", err)
 		}
 	}
 
-	return nil
+	return metrics, nil
 }
 
 func (w *Worker) getProgressCallback(task *pb.Task, taskID uuid.UUID) ffmpeg.ProgressCallback {
@@ -25,6 +25,7 @@ type (
 		CpuIdx   int
 		MaxTasks int32
 		WorkDir  string
+		VMAF     bool // ffmpeg has libvmaf, quality metrics fall back to PSNR/SSIM otherwise
 	}
 )
 
@@ -53,7 +54,8 @@ func (w *Worker) Stat() Stat {
 	}
 }
 
-func (w *Worker) Handle(task *pb.Task, taskID uuid.UUID, finish func(err error)) bool {
+// finish gets quality metrics of video renditions, nil unless the task asks for them
+func (w *Worker) Handle(task *pb.Task, taskID uuid.UUID, finish func(metrics []*pb.RenditionMetrics, err error)) bool {
 	var weight = 100 / w.opts.MaxTasks
 
 	if task.Video != nil {
@@ -88,7 +90,7 @@ func (w *Worker) Handle(task *pb.Task, taskID uuid.UUID, finish func(err error))
 	return true
 }
 
-func (w *Worker) handle(task *pb.Task, taskID uuid.UUID, done func()) (err error) {
+func (w *Worker) handle(task *pb.Task, taskID uuid.UUID, done func()) (metrics []*pb.RenditionMetrics, err error) {
 	defer done()
 
 	var lg = w.l.WithFields(log.Fields{"task_id": taskID})
@@ -98,10 +100,10 @@ func (w *Worker) handle(task *pb.Task, taskID uuid.UUID, done func()) (err error
 			lg.Errorf("handle audio: %v", err)
 		}
 	case task.Video != nil:
-		if err = w.video(task, taskID); err != nil {
+		if metrics, err = w.video(task, taskID); err != nil {
 			lg.Errorf("handle video: %v", err)
 		}
 	}
 
-	return err
+	return metrics, err
 }
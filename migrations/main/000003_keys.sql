@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS transcoder.keys (
+      task_id         UUID      NOT NULL PRIMARY KEY REFERENCES transcoder.queue (task_id) ON DELETE CASCADE
+    , key_id          UUID      NOT NULL UNIQUE
+    , key             BYTEA     NOT NULL
+    , iv              BYTEA     NOT NULL
+    , scheme          TEXT      NOT NULL
+    , created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+
+    , CONSTRAINT transcoder_keys_check_scheme CHECK ( scheme IN (
+            'cenc', 'cbcs'
+        )
+    )
+);
+
+GRANT SELECT, INSERT, UPDATE ON transcoder.keys TO admin;
+GRANT SELECT                 ON transcoder.keys TO readonly;
+
+-- +migrate Down
+DROP TABLE IF EXISTS transcoder.keys;
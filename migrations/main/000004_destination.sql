@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE transcoder.queue
+    ADD COLUMN IF NOT EXISTS destination JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- +migrate Down
+ALTER TABLE transcoder.queue
+    DROP COLUMN IF EXISTS destination;
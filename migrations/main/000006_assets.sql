@@ -0,0 +1,23 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS transcoder.assets (
+      task_id         UUID             NOT NULL REFERENCES transcoder.queue (task_id) ON DELETE CASCADE
+    , path            TEXT             NOT NULL
+    , kind            TEXT             NOT NULL
+    , location        TEXT             NOT NULL
+    , size            BIGINT           NOT NULL
+    , sha256          TEXT             NOT NULL
+    , codec           TEXT             NOT NULL DEFAULT ''
+    , bitrate         BIGINT           NOT NULL DEFAULT 0
+    , width           INT              NOT NULL DEFAULT 0
+    , height          INT              NOT NULL DEFAULT 0
+    , duration        DOUBLE PRECISION NOT NULL DEFAULT 0
+    , created_at      TIMESTAMP        NOT NULL DEFAULT CURRENT_TIMESTAMP
+
+    , PRIMARY KEY (task_id, path)
+);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON transcoder.assets TO admin;
+GRANT SELECT                         ON transcoder.assets TO readonly;
+
+-- +migrate Down
+DROP TABLE IF EXISTS transcoder.assets;
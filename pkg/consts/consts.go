@@ -10,6 +10,8 @@ const (
 	FPS30 = 30
 	FPS60 = 60
 
+	Q144p  = "144"
+	Q240p  = "240"
 	Q360p  = "360"
 	Q480p  = "480"
 	Q720p  = "720"
@@ -21,8 +23,9 @@ const (
 	TuneAnimation  = "animation"
 	TuneStillImage = "stillimage" // slideshow-like content
 
-	CodecTypeVideo = "video"
-	CodecTypeAudio = "audio"
+	CodecTypeVideo    = "video"
+	CodecTypeAudio    = "audio"
+	CodecTypeSubtitle = "subtitle"
 
 	// H264 profiles
 	ProfileMain     = "main"
@@ -32,6 +35,32 @@ const (
 	ProfileHigh444  = "high444" // supports as above as well as yuv444p and yuv444p10le
 	ProfileBaseline = "baseline"
 
+	// HEVC profiles
+	ProfileMain10 = "main10"
+
+	// color properties as named by ffprobe and ffmpeg
+	TransferPQ          = "smpte2084"    // HDR10
+	TransferHLG         = "arib-std-b67" // hybrid log-gamma
+	TransferBT709       = "bt709"
+	TransferSMPTE170M   = "smpte170m" // BT.601, same curve as BT.709
+	PrimariesBT2020     = "bt2020"
+	PrimariesBT709      = "bt709"
+	PrimariesBT470BG    = "bt470bg"   // BT.601 625 lines (PAL)
+	PrimariesSMPTE170M  = "smpte170m" // BT.601 525 lines (NTSC)
+	ColorSpaceBT2020NC  = "bt2020nc"
+	ColorSpaceBT709     = "bt709"
+	ColorSpaceBT470BG   = "bt470bg"   // BT.601 625 lines (PAL)
+	ColorSpaceSMPTE170M = "smpte170m" // BT.601 525 lines (NTSC), same matrix as PAL
+	ColorRangeTV        = "tv"        // limited
+	ColorRangePC        = "pc"        // full
+
+	// deinterlacing filters and field orders as named by idet and ffprobe
+	DeinterlaceBwdif      = "bwdif" // motion adaptive, sharper than yadif
+	DeinterlaceYadif      = "yadif"
+	FieldOrderTFF         = "tff" // top field first
+	FieldOrderBFF         = "bff" // bottom field first
+	FieldOrderProgressive = "progressive"
+
 	// H264 levels
 	Level_3_0 = "3.0"
 	Level_3_1 = "3.1"
@@ -59,6 +88,23 @@ const (
 	CodecAAC    = "aac"
 	CodecWMV3   = "wmv3"
 
+	// audio ladder codecs
+	AudioCodecAAC   = "aac"    // AAC-LC
+	AudioCodecHEAAC = "he-aac" // HE-AAC v1, AAC-LC core with SBR
+	AudioCodecOpus  = "opus"
+	AudioCodecEAC3  = "eac3" // Dolby Digital Plus, up to 5.1
+
+	// subtitle codec names, text ones can be converted to WebVTT
+	CodecSubRip  = "subrip"
+	CodecASS     = "ass"
+	CodecSSA     = "ssa"
+	CodecMovText = "mov_text"
+	CodecWebVTT  = "webvtt"
+	CodecText    = "text"
+	CodecPGS     = "hdmv_pgs_subtitle"
+	CodecDVBSub  = "dvb_subtitle"
+	CodecDVDSub  = "dvd_subtitle"
+
 	TransposeCclock   = "cclock"
 	TransposeClock    = "clock"
 	TransposeReversal = "reversal"
@@ -0,0 +1,274 @@
+package dash
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	ProfileOnDemand = "urn:mpeg:dash:profile:isoff-on-demand:2011"
+	ProfileLive     = "urn:mpeg:dash:profile:isoff-live:2011"
+	ProfileMain     = "urn:mpeg:dash:profile:isoff-main:2011"
+
+	ContentTypeVideo = "video"
+	ContentTypeAudio = "audio"
+	ContentTypeText  = "text"
+
+	MimeTypeVideo  = "video/mp4"
+	MimeTypeAudio  = "audio/mp4"
+	MimeTypeWebVTT = "text/vtt"
+
+	// common encryption signaling, value is scheme (cenc/cbcs)
+	SchemeMP4Protection = "urn:mpeg:dash:mp4protection:2011"
+	// DASH-IF ClearKey, license url in dashif:laurl
+	SchemeClearKey = "urn:uuid:e2719d58-a985-b3c9-781a-b030af78d30e"
+	// W3C common system id, carries pssh with key ids
+	SchemeW3CCommon = "urn:uuid:1077efec-c0b2-4d02-ace3-3c1e52e2fb4b"
+
+	audioChannelConfigurationScheme      = "urn:mpeg:dash:23003:3:audio_channel_configuration:2011"
+	dolbyAudioChannelConfigurationScheme = "tag:dolby.com,2014:dash:audio_channel_configuration:2011"
+	roleScheme                           = "urn:mpeg:dash:role:2011"
+
+	// ISO/IEC 23001-8 code points
+	cicpPrimariesScheme = "urn:mpeg:mpegB:cicp:ColourPrimaries"
+	cicpTransferScheme  = "urn:mpeg:mpegB:cicp:TransferCharacteristics"
+	cicpMatrixScheme    = "urn:mpeg:mpegB:cicp:MatrixCoefficients"
+)
+
+type (
+	MPD struct {
+		XMLName                   xml.Name `xml:"MPD"`
+		XMLNS                     string   `xml:"xmlns,attr"`
+		XMLNSCenc                 string   `xml:"xmlns:cenc,attr,omitempty"`
+		XMLNSDashIF               string   `xml:"xmlns:dashif,attr,omitempty"`
+		Profiles                  string   `xml:"profiles,attr"`
+		Type                      string   `xml:"type,attr"`
+		MediaPresentationDuration Duration `xml:"mediaPresentationDuration,attr"`
+		MinBufferTime             Duration `xml:"minBufferTime,attr"`
+		Periods                   []Period `xml:"Period"`
+	}
+
+	Period struct {
+		ID             string          `xml:"id,attr"`
+		Start          Duration        `xml:"start,attr"`
+		AdaptationSets []AdaptationSet `xml:"AdaptationSet"`
+	}
+
+	AdaptationSet struct {
+		ID                      int                 `xml:"id,attr"`
+		ContentType             string              `xml:"contentType,attr"`
+		MimeType                string              `xml:"mimeType,attr"`
+		Lang                    string              `xml:"lang,attr,omitempty"`
+		SegmentAlignment        bool                `xml:"segmentAlignment,attr"`
+		SubsegmentAlignment     bool                `xml:"subsegmentAlignment,attr,omitempty"`
+		SubsegmentStartsWithSAP int                 `xml:"subsegmentStartsWithSAP,attr,omitempty"`
+		StartWithSAP            int                 `xml:"startWithSAP,attr,omitempty"`
+		MaxWidth                int                 `xml:"maxWidth,attr,omitempty"`
+		MaxHeight               int                 `xml:"maxHeight,attr,omitempty"`
+		ContentProtections      []ContentProtection `xml:"ContentProtection,omitempty"`
+		Roles                   []Descriptor        `xml:"Role,omitempty"`
+		EssentialProperties     []Descriptor        `xml:"EssentialProperty,omitempty"`
+		SupplementalProperties  []Descriptor        `xml:"SupplementalProperty,omitempty"`
+		Label                   string              `xml:"Label,omitempty"`
+		Representations         []Representation    `xml:"Representation"`
+	}
+
+	Representation struct {
+		ID                        string           `xml:"id,attr"`
+		Bandwidth                 int64            `xml:"bandwidth,attr"`
+		Codecs                    string           `xml:"codecs,attr,omitempty"`
+		Width                     int              `xml:"width,attr,omitempty"`
+		Height                    int              `xml:"height,attr,omitempty"`
+		FrameRate                 string           `xml:"frameRate,attr,omitempty"`
+		SAR                       string           `xml:"sar,attr,omitempty"`
+		AudioSamplingRate         string           `xml:"audioSamplingRate,attr,omitempty"`
+		AudioChannelConfiguration []Descriptor     `xml:"AudioChannelConfiguration,omitempty"`
+		BaseURL                   string           `xml:"BaseURL,omitempty"`
+		SegmentBase               *SegmentBase     `xml:"SegmentBase,omitempty"`
+		SegmentList               *SegmentList     `xml:"SegmentList,omitempty"`
+		SegmentTemplate           *SegmentTemplate `xml:"SegmentTemplate,omitempty"`
+	}
+
+	Descriptor struct {
+		SchemeIDURI string `xml:"schemeIdUri,attr"`
+		Value       string `xml:"value,attr,omitempty"`
+	}
+
+	ContentProtection struct {
+		SchemeIDURI string `xml:"schemeIdUri,attr"`
+		Value       string `xml:"value,attr,omitempty"`
+		DefaultKID  string `xml:"cenc:default_KID,attr,omitempty"`
+		PSSH        string `xml:"cenc:pssh,omitempty"`    // base64 encoded box
+		LicenseURL  string `xml:"dashif:laurl,omitempty"` // ClearKey license server
+	}
+
+	// single-file addressing through sidx box
+	SegmentBase struct {
+		Timescale      uint32         `xml:"timescale,attr,omitempty"`
+		IndexRange     string         `xml:"indexRange,attr"`
+		Initialization Initialization `xml:"Initialization"`
+	}
+
+	// single-file addressing through explicit byte-ranges
+	SegmentList struct {
+		Timescale       uint32           `xml:"timescale,attr,omitempty"`
+		Initialization  Initialization   `xml:"Initialization"`
+		SegmentTimeline *SegmentTimeline `xml:"SegmentTimeline,omitempty"`
+		SegmentURLs     []SegmentURL     `xml:"SegmentURL"`
+	}
+
+	// separate file per segment, see NumberTemplate
+	SegmentTemplate struct {
+		Timescale       uint32           `xml:"timescale,attr,omitempty"`
+		Initialization  string           `xml:"initialization,attr"`
+		Media           string           `xml:"media,attr"`
+		StartNumber     int              `xml:"startNumber,attr"`
+		SegmentTimeline *SegmentTimeline `xml:"SegmentTimeline,omitempty"`
+	}
+
+	Initialization struct {
+		SourceURL string `xml:"sourceURL,attr,omitempty"`
+		Range     string `xml:"range,attr,omitempty"`
+	}
+
+	SegmentTimeline struct {
+		S []S `xml:"S"`
+	}
+
+	S struct {
+		T uint64 `xml:"t,attr"`
+		D uint64 `xml:"d,attr"`
+	}
+
+	SegmentURL struct {
+		Media      string `xml:"media,attr,omitempty"`
+		MediaRange string `xml:"mediaRange,attr,omitempty"`
+	}
+)
+
+func NewMPD(profile string, duration, minBufferTime float64) MPD {
+	return MPD{
+		XMLNS:                     "urn:mpeg:dash:schema:mpd:2011",
+		Profiles:                  profile,
+		Type:                      "static",
+		MediaPresentationDuration: Duration(duration),
+		MinBufferTime:             Duration(minBufferTime),
+	}
+}
+
+func AudioChannels(channels int) []Descriptor {
+	return []Descriptor{{
+		SchemeIDURI: audioChannelConfigurationScheme,
+		Value:       fmt.Sprint(channels),
+	}}
+}
+
+// channel masks of AC-3/E-AC-3 layouts, ETSI TS 102 366 annex I
+var dolbyChannelMasks = map[int]string{
+	1: "4000", // C
+	2: "A000", // L, R
+	6: "F801", // L, C, R, Ls, Rs, LFE
+}
+
+// AudioChannels for AC-3/E-AC-3, Dolby scheme carries the channel mask
+func DolbyAudioChannels(channels int) []Descriptor {
+	mask, ok := dolbyChannelMasks[channels]
+	if !ok {
+		return AudioChannels(channels)
+	}
+	return []Descriptor{{
+		SchemeIDURI: dolbyAudioChannelConfigurationScheme,
+		Value:       mask,
+	}}
+}
+
+func Role(value string) Descriptor {
+	return Descriptor{SchemeIDURI: roleScheme, Value: value}
+}
+
+// HDR signalling as DASH-IF recommends, players that don't know
+// the transfer characteristics skip the adaptation set
+func ColorProperties(primaries, transfer, matrix int) (essential, supplemental []Descriptor) {
+	essential = []Descriptor{{SchemeIDURI: cicpTransferScheme, Value: strconv.Itoa(transfer)}}
+	supplemental = []Descriptor{
+		{SchemeIDURI: cicpPrimariesScheme, Value: strconv.Itoa(primaries)},
+		{SchemeIDURI: cicpMatrixScheme, Value: strconv.Itoa(matrix)},
+	}
+	return essential, supplemental
+}
+
+// adds namespaces used by ContentProtection elements
+func (m *MPD) Protected() {
+	m.XMLNSCenc = "urn:mpeg:cenc:2013"
+	m.XMLNSDashIF = "https://dashif.org/CPS"
+}
+
+// inclusive byte range "first-last"
+func Range(offset, length int64) string {
+	return fmt.Sprintf("%d-%d", offset, offset+length-1)
+}
+
+// converts printf-style file name pattern with
+// single integer verb (e.g. seg_%05d.m4s) into $Number$ template
+func NumberTemplate(pattern string) string {
+	var start = strings.Index(pattern, "%")
+	if start < 0 {
+		return pattern
+	}
+
+	var end = strings.IndexByte(pattern[start:], 'd')
+	if end < 0 {
+		return pattern
+	}
+	end += start + 1
+
+	return pattern[:start] + "$Number" + pattern[start:end] + "$" + pattern[end:]
+}
+
+// ISO 8601 duration in seconds, e.g. PT12.345S
+type Duration float64
+
+func (d Duration) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: fmt.Sprintf("PT%.3fS", float64(d))}, nil
+}
+
+func (m MPD) Encode(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	var enc = xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writes manifest to dstDir/fname, returns full path
+func Write(m MPD, dstDir, fname string) (string, error) {
+	if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	var out = filepath.Join(dstDir, fname)
+
+	f, err := os.Create(out)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := m.Encode(f); err != nil {
+		return "", err
+	}
+
+	return out, f.Sync()
+}
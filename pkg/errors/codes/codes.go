@@ -11,6 +11,7 @@ const (
 	StitchSources      = "STITCH_SOURCES_ERROR"
 	FragmentSources    = "FRAGMENT_SOURCES_ERROR"
 	EncryptSources     = "ENCRYPT_SOURCES_ERROR"
+	GenerateManifests  = "GENERATE_MANIFESTS_ERROR"
 	GeneratePoster     = "GENERATE_POSTER_ERROR"
 	DB                 = "DB_ERROR"
 	Redis              = "REDIS_ERROR"
@@ -2,6 +2,7 @@ package errors
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/timohahaa/transcoder/pkg/errors/codes"
 	"github.com/timohahaa/transcoder/pkg/ffmpeg"
@@ -16,9 +17,22 @@ func New(reason, domain string, metadata map[string]string) *pb.Error {
 	}
 }
 
+// Issues is a list of independent failures (e.g. validation),
+// every issue gets its own metadata key: issue_0, issue_1, ...
+type Issues []string
+
+func (i Issues) Error() string {
+	return strings.Join(i, "; ")
+}
+
 func extractMeta(err error) map[string]string {
 	var meta = make(map[string]string)
 	switch e := err.(type) {
+	case Issues:
+		meta["issues"] = strconv.Itoa(len(e))
+		for n, issue := range e {
+			meta["issue_"+strconv.Itoa(n)] = issue
+		}
 	case *ffmpeg.Error:
 		meta["code"] = e.Code
 		meta["message"] = e.Message
@@ -110,6 +124,14 @@ func EncryptSources(err error) *pb.Error {
 	return New(codes.EncryptSources, "assembler", extractMeta(err))
 }
 
+func UploadAssets(err error) *pb.Error {
+	return New(codes.Upload, "assembler", extractMeta(err))
+}
+
+func GenerateManifests(err error) *pb.Error {
+	return New(codes.GenerateManifests, "assembler", extractMeta(err))
+}
+
 func Unmux(err error) *pb.Error {
 	return New(codes.Unmux, "splitter", extractMeta(err))
 }
@@ -12,7 +12,14 @@ import (
 
 var ErrNoAudios = errors.New("no audios")
 
-func UnmuxAudios(ctx context.Context, info *ffprobe.Info, srcFile, dstDir string) ([]string, error) {
+// UnmuxAudios copies streams to dstDir/orig_audio_N, files are in the order of streams,
+// every audio of info is unmuxed if streams is nil
+func UnmuxAudios(
+	ctx context.Context,
+	info *ffprobe.Info,
+	srcFile, dstDir string,
+	streams []ffprobe.Stream,
+) ([]string, error) {
 	if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
 		return nil, err
 	}
@@ -25,7 +32,10 @@ func UnmuxAudios(ctx context.Context, info *ffprobe.Info, srcFile, dstDir string
 		}
 	}
 
-	var audios = info.GetAllAudios()
+	var audios = streams
+	if audios == nil {
+		audios = info.GetAllAudios()
+	}
 	if len(audios) == 0 {
 		return nil, ErrNoAudios
 	}
@@ -0,0 +1,90 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+var ErrNoVideoSize = errors.New("no encoded video size in ffmpeg output")
+
+// fast constant quality encode of samples spread over the source,
+// the bitrate it takes measures how hard the content is to encode
+type ComplexityProbe struct {
+	CRF     int
+	Height  int     // samples are downscaled to it
+	Samples int     // whole source is encoded if it is shorter than all samples
+	Sample  float64 // seconds
+}
+
+// ProbeBitrate returns bits per second of the probe encode of src
+func ProbeBitrate(ctx context.Context, src string, duration float64, p ComplexityProbe) (int64, error) {
+	var (
+		args    = []string{"-hide_banner", "-nostats"}
+		filter  string
+		encoded = duration
+	)
+
+	if duration <= float64(p.Samples)*p.Sample {
+		args = append(args, "-i", src)
+		filter = fmt.Sprintf("[0:v:0]scale=-2:%d", p.Height)
+	} else {
+		// input seeking decodes only samples, not the whole source
+		for i := range p.Samples {
+			var start = duration*float64(2*i+1)/float64(2*p.Samples) - p.Sample/2
+			args = append(args,
+				"-ss", strconv.FormatFloat(start, 'f', 3, 64),
+				"-t", strconv.FormatFloat(p.Sample, 'f', 3, 64),
+				"-i", src,
+			)
+			filter += fmt.Sprintf("[%d:v:0]", i)
+		}
+		filter += fmt.Sprintf("concat=n=%d:v=1:a=0,scale=-2:%d", p.Samples, p.Height)
+		encoded = float64(p.Samples) * p.Sample
+	}
+
+	args = append(args,
+		"-filter_complex", filter,
+		"-c:v", "libx264",
+		"-preset", "ultrafast",
+		"-crf", strconv.Itoa(p.CRF),
+		"-an", "-sn",
+		"-f", "null",
+		"-",
+	)
+
+	stderr, err := executeStderr(ctx, src, args)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := parseVideoSize(stderr)
+	if err != nil {
+		return 0, err
+	}
+	if encoded <= 0 {
+		return 0, nil
+	}
+
+	return int64(math.Round(float64(size*8) / encoded)), nil
+}
+
+// final stats line: video:1234KiB audio:0KiB ..., kB in older versions
+var videoSizeRe = regexp.MustCompile(`video:\s*([0-9.]+)\s*(?:KiB|kB)`)
+
+// returns encoded video size in bytes
+func parseVideoSize(stderr string) (int64, error) {
+	var m = videoSizeRe.FindAllStringSubmatch(stderr, -1)
+	if len(m) == 0 {
+		return 0, ErrNoVideoSize
+	}
+
+	kib, err := strconv.ParseFloat(m[len(m)-1][1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrNoVideoSize, err)
+	}
+	return int64(kib * 1024), nil
+}
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+
+	"github.com/timohahaa/transcoder/pkg/consts"
 )
 
 // all cases:
@@ -28,6 +30,132 @@ type AudioPreset struct {
 	PadAfter     float64
 	TrimBefore   float64
 	TrimDuration float64
+
+	// second pass of loudness normalization, disabled if nil
+	Loudnorm *Loudnorm
+
+	// output stream tags, not set if empty
+	Language string
+	Title    string
+
+	// outputs of one run, single AAC-LC output with Channels/Bitrate/SampleRate if empty
+	Renditions []AudioRendition
+
+	// of the source, e.g. 5.1(side), padding is generated with it
+	// so concat doesn't have to guess, number of Channels is used if empty
+	ChannelLayout string
+	// mono/stereo renditions of multichannel source, ffmpeg default matrix if nil
+	Downmix *Downmix
+}
+
+type AudioRendition struct {
+	Name          string // output file name without extension, "audio" if empty
+	Codec         string // consts.AudioCodec*, AAC-LC if empty
+	Bitrate       int64
+	Channels      int
+	SampleRate    int64
+	ChannelLayout string // kept multichannel layout, e.g. 5.1, empty for mono/stereo
+	// measured on the downmix of multichannel source, downmixed renditions are not normalized if nil
+	Loudnorm *Loudnorm
+}
+
+// Downmix levels are linear gains of center/surround/LFE channels mixed into left and right
+type Downmix struct {
+	Center   float64
+	Surround float64
+	LFE      float64
+	Encoding string // matrix encoding for surround decoders: dolby, dplii, none if empty
+}
+
+func (d Downmix) filter(channels int) string {
+	var encoding = d.Encoding
+	if encoding == "" {
+		encoding = "none"
+	}
+
+	var layout = "stereo"
+	if channels == 1 {
+		layout = "mono"
+	}
+
+	// aformat makes aresample do the rematrixing
+	return fmt.Sprintf(
+		"aresample=clev=%s:slev=%s:lfe_mix_level=%s:matrix_encoding=%s,aformat=channel_layouts=%s",
+		strconv.FormatFloat(d.Center, 'f', -1, 64),
+		strconv.FormatFloat(d.Surround, 'f', -1, 64),
+		strconv.FormatFloat(d.LFE, 'f', -1, 64),
+		encoding,
+		layout,
+	)
+}
+
+// filter applied to a single rendition after pad/trim, normalization follows
+// the layout conversion, so downmix gains don't shift loudness off the target
+func (a *AudioPreset) renditionFilter(r AudioRendition) string {
+	var (
+		filter   string
+		loudnorm = a.Loudnorm
+	)
+	switch {
+	case r.ChannelLayout != "":
+		filter = "aformat=channel_layouts=" + r.ChannelLayout
+	case a.Downmix != nil:
+		filter = a.Downmix.filter(r.Channels)
+		loudnorm = r.Loudnorm
+	}
+
+	if loudnorm != nil {
+		filter = joinFilters(filter, loudnorm.filter())
+	}
+	return filter
+}
+
+// silence for padding, same layout as the source
+func (a *AudioPreset) anullsrc(duration float64) []string {
+	var layout = a.ChannelLayout
+	if layout == "" {
+		// a bare number is a channel mask, not a count
+		layout = fmt.Sprintf("%dc", a.Channels)
+	}
+
+	return []string{
+		"-f", "lavfi",
+		"-t", strconv.FormatFloat(duration, 'f', -1, 64),
+		"-i", fmt.Sprintf("anullsrc=channel_layout=%s:sample_rate=%d", layout, a.SampleRate),
+	}
+}
+
+func (a *AudioPreset) renditions() []AudioRendition {
+	if len(a.Renditions) > 0 {
+		return a.Renditions
+	}
+	return []AudioRendition{{
+		Codec:      consts.AudioCodecAAC,
+		Bitrate:    a.Bitrate,
+		Channels:   a.Channels,
+		SampleRate: a.SampleRate,
+	}}
+}
+
+func (r AudioRendition) fileName() string {
+	if r.Name == "" {
+		return "audio.mp4"
+	}
+	return r.Name + ".mp4"
+}
+
+func (r AudioRendition) codecArgs() []string {
+	switch r.Codec {
+	case consts.AudioCodecHEAAC:
+		// explicit SBR signaling in mp4, so manifests get mp4a.40.5
+		return []string{"-c:a", "libfdk_aac", "-profile:a", "aac_he"}
+	case consts.AudioCodecOpus:
+		return []string{"-c:a", "libopus"}
+	case consts.AudioCodecEAC3:
+		return []string{"-c:a", "eac3"}
+	default:
+		return []string{"-c:a", "libfdk_aac"}
+	}
 }
 
 func (a *AudioPreset) prepareCmd(src string) (input []string, filter string) {
@@ -74,21 +202,13 @@ func (a *AudioPreset) padOnly(src string) (input []string, filter string) {
 	// input
 	{
 		if a.PadBefore > 0 {
-			input = append(input,
-				"-f", "lavfi",
-				"-t", strconv.FormatFloat(a.PadBefore, 'f', -1, 64),
-				"-i", fmt.Sprintf("anullsrc=channel_layout=%d:sample_rate=%d", a.Channels, a.SampleRate),
-			)
+			input = append(input, a.anullsrc(a.PadBefore)...)
 		}
 
 		input = append(input, "-i", src)
 
 		if a.PadAfter > 0 {
-			input = append(input,
-				"-f", "lavfi",
-				"-t", strconv.FormatFloat(a.PadAfter, 'f', -1, 64),
-				"-i", fmt.Sprintf("anullsrc=channel_layout=%d:sample_rate=%d", a.Channels, a.SampleRate),
-			)
+			input = append(input, a.anullsrc(a.PadAfter)...)
 		}
 	}
 	// filter
@@ -113,11 +233,7 @@ func (a *AudioPreset) padOnly(src string) (input []string, filter string) {
 func (a *AudioPreset) padTrim(src string) (input []string, filter string) {
 	// input
 	{
-		input = append(input,
-			"-f", "lavfi",
-			"-t", strconv.FormatFloat(a.PadBefore, 'f', -1, 64),
-			"-i", fmt.Sprintf("anullsrc=channel_layout=%d:sample_rate=%d", a.Channels, a.SampleRate),
-		)
+		input = append(input, a.anullsrc(a.PadBefore)...)
 		input = append(input, "-i", src)
 	}
 	// filter
@@ -134,11 +250,7 @@ func (a *AudioPreset) trimPad(src string) (input []string, filter string) {
 	// input
 	{
 		input = append(input, "-i", src)
-		input = append(input,
-			"-f", "lavfi",
-			"-t", strconv.FormatFloat(a.PadAfter, 'f', -1, 64),
-			"-i", fmt.Sprintf("anullsrc=channel_layout=%d:sample_rate=%d", a.Channels, a.SampleRate),
-		)
+		input = append(input, a.anullsrc(a.PadAfter)...)
 	}
 	// filter
 	{
@@ -147,45 +259,96 @@ func (a *AudioPreset) trimPad(src string) (input []string, filter string) {
 	return
 }
 
+// EncodeAudio produces every rendition of the preset in one run,
+// pad/trim and normalization are done once and split between outputs
 func EncodeAudio(
 	ctx context.Context,
 	cpuIdx []int,
 	src, dst string,
 	preset AudioPreset,
-) (string, error) {
+) ([]Quality, error) {
 	if err := os.MkdirAll(dst, os.ModePerm); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var (
-		output = filepath.Join(dst, "audio.mp4")
-		args   = []string{
+		args = []string{
 			"-xerror",
 			"-hide_banner",
 			"-y",
 		}
 		input, filter = preset.prepareCmd(src)
+		renditions    = preset.renditions()
+		qualities     = make([]Quality, 0, len(renditions))
 	)
 
 	args = append(args, input...)
 
+	if len(renditions) == 1 {
+		filter = joinFilters(filter, preset.renditionFilter(renditions[0]))
+	} else {
+		var split = fmt.Sprintf("asplit=%d", len(renditions))
+		for i := range renditions {
+			split += fmt.Sprintf("[split%d]", i)
+		}
+		filter = joinFilters(filter, split)
+
+		for i, r := range renditions {
+			var f = preset.renditionFilter(r)
+			if f == "" {
+				f = "anull"
+			}
+			filter += fmt.Sprintf(";[split%d]%s[out%d]", i, f, i)
+		}
+	}
+
 	if filter != "" {
 		args = append(args, "-filter_complex", filter)
 	}
 
-	args = append(args,
-		"-c:a", "libfdk_aac",
-		"-vn",
-		"-b:a", strconv.FormatInt(preset.Bitrate, 10),
-		"-ar", strconv.FormatInt(preset.SampleRate, 10),
-		"-ac", strconv.Itoa(preset.Channels),
-		"-movflags", "+faststart",
-		output,
-	)
+	for i, r := range renditions {
+		var output = filepath.Join(dst, r.fileName())
+
+		if len(renditions) > 1 {
+			args = append(args, "-map", fmt.Sprintf("[out%d]", i))
+		}
+		args = append(args, r.codecArgs()...)
+		args = append(args,
+			"-vn",
+			"-b:a", strconv.FormatInt(r.Bitrate, 10),
+			"-ar", strconv.FormatInt(r.SampleRate, 10),
+			"-ac", strconv.Itoa(r.Channels),
+			"-movflags", "+faststart",
+		)
+		if preset.Language != "" {
+			args = append(args, "-metadata:s:a:0", "language="+preset.Language)
+		}
+		if preset.Title != "" {
+			args = append(args, "-metadata:s:a:0", "title="+preset.Title)
+		}
+		args = append(args, output)
+
+		qualities = append(qualities, Quality{
+			Name: r.Name,
+			Path: output,
+		})
+	}
 
 	if _, err := scope(ctx, cpuIdx, src, nil, args, DiscardProgress); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return output, nil
+	return qualities, nil
+}
+
+// chains filter after the last one of the graph
+func joinFilters(graph, filter string) string {
+	switch {
+	case graph == "":
+		return filter
+	case filter == "":
+		return graph
+	default:
+		return graph + "," + filter
+	}
 }
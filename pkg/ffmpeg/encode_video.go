@@ -8,27 +8,56 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+
+	"github.com/timohahaa/transcoder/pkg/consts"
 )
 
 type Preset struct {
-	Quality        string // 360, 720, 1440, etc...
-	MaxBitRate     int64
-	MinBitRate     int64
-	FPS            string
-	Codec          string
-	Bufsize        int64
-	GOPSeconds     int32
-	Profile        string
-	Level          string
-	CRF            int32 // -cq for GPU and -crf for CPU
-	ColorTrc       string
-	ColorSpace     string
-	ColorPrimaries string
-	Tune           string // psnr/ssim/grain/zerolatency/animation/film - content type
-	Transpose      string // clock/cclock/flip
-	IsVertical     bool
-	Width          int
-	Height         int
+	Quality          string // 360, 720, 1440, etc...
+	MaxBitRate       int64
+	MinBitRate       int64
+	FPS              string
+	Codec            string // consts.CodecH264, consts.CodecHEVC or consts.CodecAV1
+	Bufsize          int64
+	GOPSeconds       int32
+	ChunkStart       float64 // seconds, keyframes are placed on the GOP grid of the whole video
+	Profile          string
+	Level            string
+	CRF              int32 // -cq for GPU and -crf for CPU
+	ColorTrc         string
+	ColorSpace       string
+	ColorPrimaries   string
+	Tune             string // psnr/ssim/grain/zerolatency/animation/film - content type
+	Transpose        string // clock/cclock/flip
+	IsVertical       bool
+	Width            int
+	Height           int
+	Tonemap          string       // transfer of HDR source tone mapped to SDR BT.709, empty if none
+	SourceColorSpace string       // matrix of SDR source, converted into ColorSpace if they differ
+	SourceColorRange string       // consts.ColorRangePC sources are converted to limited range
+	HDR              bool         // 10-bit rendition keeping source transfer, Color* are of the output
+	Mastering        *HDRMetadata // HDR renditions only, nil if unknown
+	Deinterlace      *Deinterlace // nil for progressive sources, FPS is of the deinterlaced video
+}
+
+// static HDR metadata, chromaticity coordinates and luminance in cd/m2
+type HDRMetadata struct {
+	Red, Green, Blue, WhitePoint [2]float64
+	MaxLuminance, MinLuminance   float64
+	MaxCLL, MaxFALL              int
+}
+
+// rendition name, H.264 renditions are named by quality,
+// others get codec suffix, e.g. 1080_hevc, HDR ones _hdr suffix, e.g. 1080_hevc_hdr
+func (p Preset) Name() string {
+	var name = p.Quality
+	if p.Codec != "" && p.Codec != consts.CodecH264 {
+		name += "_" + p.Codec
+	}
+	if p.HDR {
+		name += "_hdr"
+	}
+	return name
 }
 
 type (
@@ -60,15 +89,15 @@ func EncodeCPU(
 
 	for _, p := range ps {
 
-		var qualityBasedDst = filepath.Join(dst, p.Quality)
+		var qualityBasedDst = filepath.Join(dst, p.Name())
 		if err := os.MkdirAll(qualityBasedDst, os.ModePerm); err != nil {
 			return nil, fmt.Errorf("make dir: %v", err)
 		}
 
-		var path = filepath.Join(qualityBasedDst, fmt.Sprintf("%s.mp4", p.Quality))
+		var path = filepath.Join(qualityBasedDst, fmt.Sprintf("%s.mp4", p.Name()))
 
 		args = append(args,
-			"-c:v", p.Codec,
+			"-c:v", videoEncoder(p.Codec),
 			"-profile:v", p.Profile,
 			"-vf", vfOptsCPU(p),
 		)
@@ -83,16 +112,15 @@ func EncodeCPU(
 		args = append(args,
 			"-bufsize", strconv.FormatInt(p.Bufsize, 10),
 			"-crf", strconv.FormatInt(int64(p.CRF), 10),
-			// "-colorspace", p.ColorSpace,
-			// "-color_primaries", p.ColorPrimaries,
-			// "-color_trc", p.ColorTrc,
 		)
+		args = append(args, colorOpts(p)...)
 
 		gop, err := gopOpts(p)
 		if err != nil {
 			return nil, err
 		}
 		args = append(args, gop...)
+		args = append(args, codecOpts(p)...)
 
 		args = append(args,
 			"-an",
@@ -101,7 +129,7 @@ func EncodeCPU(
 		)
 
 		qualities = append(qualities, Quality{
-			Name: p.Quality,
+			Name: p.Name(),
 			Path: path,
 		})
 	}
@@ -117,18 +145,141 @@ func EncodeCPU(
 	}, nil
 }
 
+func videoEncoder(codec string) string {
+	switch codec {
+	case consts.CodecHEVC:
+		return "libx265"
+	case consts.CodecAV1:
+		return "libsvtav1"
+	default:
+		return codec
+	}
+}
+
+// x265 and SVT-AV1 ignore -sc_threshold and -level,
+// closed GOPs keep keyframes of every codec at the same timestamps
+func codecOpts(p Preset) []string {
+	switch p.Codec {
+	case consts.CodecHEVC:
+		var params = "scenecut=0"
+		if p.Level != "" {
+			params += ":level-idc=" + p.Level
+		}
+		if p.HDR {
+			params += ":repeat-headers=1"
+			if p.ColorTrc == consts.TransferPQ {
+				params += ":hdr10=1:hdr10-opt=1"
+			}
+			if m := p.Mastering; m != nil {
+				params += ":master-display=" + m.x265()
+				params += fmt.Sprintf(":max-cll=%d,%d", m.MaxCLL, m.MaxFALL)
+			}
+		}
+		return []string{
+			"-tag:v", "hvc1", // hev1 is not played by Apple devices
+			"-flags", "+cgop",
+			"-forced-idr", "1", // CRA otherwise, segments start with IDR
+			"-x265-params", params,
+		}
+	case consts.CodecAV1:
+		var (
+			opts = []string{
+				"-preset", "8",
+				"-flags", "+cgop",
+			}
+			params []string
+		)
+		if p.Level != "" {
+			params = append(params, "level="+p.Level)
+		}
+		if m := p.Mastering; p.HDR && m != nil {
+			params = append(params,
+				"mastering-display="+m.svtav1(),
+				fmt.Sprintf("content-light=%d,%d", m.MaxCLL, m.MaxFALL),
+			)
+		}
+		if len(params) > 0 {
+			opts = append(opts, "-svtav1-params", strings.Join(params, ":"))
+		}
+		return opts
+	default:
+		if m := p.Mastering; p.HDR && m != nil {
+			return []string{
+				"-x264-params",
+				fmt.Sprintf("mastering-display=%s:cll=%d,%d", m.x265(), m.MaxCLL, m.MaxFALL),
+			}
+		}
+		return nil
+	}
+}
+
+// G(x,y)B(x,y)R(x,y)WP(x,y)L(max,min) in 0.00002 and 0.0001 cd/m2 units, x264 takes the same
+func (m HDRMetadata) x265() string {
+	var c = func(xy [2]float64) string {
+		return fmt.Sprintf("(%.0f,%.0f)", xy[0]*50000, xy[1]*50000)
+	}
+	return fmt.Sprintf(
+		"G%sB%sR%sWP%sL(%.0f,%.0f)",
+		c(m.Green), c(m.Blue), c(m.Red), c(m.WhitePoint),
+		m.MaxLuminance*10000, m.MinLuminance*10000,
+	)
+}
+
+// same layout as x265 one in plain units
+func (m HDRMetadata) svtav1() string {
+	var c = func(xy [2]float64) string {
+		return fmt.Sprintf("(%.4f,%.4f)", xy[0], xy[1])
+	}
+	return fmt.Sprintf(
+		"G%sB%sR%sWP%sL(%.4f,%.4f)",
+		c(m.Green), c(m.Blue), c(m.Red), c(m.WhitePoint),
+		m.MaxLuminance, m.MinLuminance,
+	)
+}
+
+// every output is tagged explicitly, players guess colors of untagged ones by size
+func colorOpts(p Preset) []string {
+	var opts = []string{"-color_range", consts.ColorRangeTV}
+	if p.ColorSpace != "" {
+		opts = append(opts, "-colorspace", p.ColorSpace)
+	}
+	if p.ColorPrimaries != "" {
+		opts = append(opts, "-color_primaries", p.ColorPrimaries)
+	}
+	if p.ColorTrc != "" {
+		opts = append(opts, "-color_trc", p.ColorTrc)
+	}
+	return opts
+}
+
 func vfOptsCPU(p Preset) string {
-	var opts = []string{
-		fmt.Sprintf("fps=%s", p.FPS),
-		"format=yuv420p",
-		"setsar=1/1",
+	var opts []string
+	if p.Deinterlace != nil {
+		opts = append(opts, p.Deinterlace.filter())
 	}
+	opts = append(opts, fmt.Sprintf("fps=%s", p.FPS), "setsar=1/1")
 
 	if p.IsVertical {
 		p.Width, p.Height = p.Height, p.Width
 	}
 
-	opts = append(opts, fmt.Sprintf("scale=%d:%d", p.Width, p.Height))
+	// tone mapping converts matrix and format itself after scaling,
+	// SDR sources are converted by the scaler, so format follows it
+	// and yuvj sources are not expanded to limited range twice
+	var scale = fmt.Sprintf("scale=%d:%d", p.Width, p.Height)
+	if p.Tonemap == "" {
+		scale = joinOpts(scale, ColorConvertOpts(p.SourceColorSpace, p.ColorSpace, p.SourceColorRange)...)
+	}
+	opts = append(opts, scale)
+
+	switch {
+	case p.Tonemap != "":
+		opts = append(opts, TonemapFilter(p.Tonemap))
+	case p.HDR:
+		opts = append(opts, "format=yuv420p10le")
+	default:
+		opts = append(opts, "format=yuv420p")
+	}
 
 	// ffmpeg does transpose on cpu automatically
 	// if p.Transpose != "" {
@@ -138,6 +289,57 @@ func vfOptsCPU(p Preset) string {
 	return strings.Join(opts, ",")
 }
 
+// ColorConvertOpts returns scale filter options converting matrix from into to
+// and full range into limited one, empty if nothing is to be converted
+func ColorConvertOpts(from, to, fromRange string) []string {
+	var (
+		in, out = swsMatrix(from), swsMatrix(to)
+		opts    []string
+	)
+	if in != "" && out != "" && in != out {
+		opts = append(opts, "in_color_matrix="+in, "out_color_matrix="+out)
+	}
+	if fromRange == consts.ColorRangePC {
+		opts = append(opts, "in_range=pc", "out_range=tv")
+	}
+	return opts
+}
+
+// swscale names of matrices, empty for ones it can't convert
+func swsMatrix(space string) string {
+	switch space {
+	case consts.ColorSpaceBT470BG, consts.ColorSpaceSMPTE170M:
+		return "bt601"
+	case consts.ColorSpaceBT709, "fcc", "smpte240m":
+		return space
+	case consts.ColorSpaceBT2020NC, "bt2020c":
+		return "bt2020"
+	}
+	return ""
+}
+
+// filter with options appended, e.g. scale=1280:720:in_range=pc
+func joinOpts(filter string, opts ...string) string {
+	for _, o := range opts {
+		filter += ":" + o
+	}
+	return filter
+}
+
+// TonemapFilter converts HDR BT.2020 video of transfer to SDR BT.709 yuv420p,
+// linear light is tone mapped with hable curve, so highlights are not clipped
+func TonemapFilter(transfer string) string {
+	return strings.Join([]string{
+		fmt.Sprintf("zscale=tin=%s:min=%s:pin=%s:t=linear:npl=100",
+			transfer, consts.ColorSpaceBT2020NC, consts.PrimariesBT2020),
+		"format=gbrpf32le",
+		"zscale=p=" + consts.PrimariesBT709,
+		"tonemap=tonemap=hable:desat=0",
+		fmt.Sprintf("zscale=t=%s:m=%s:r=tv", consts.TransferBT709, consts.ColorSpaceBT709),
+		"format=yuv420p",
+	}, ",")
+}
+
 func gopOpts(p Preset) ([]string, error) {
 	var (
 		fps, err = floatFPS(p.FPS)
@@ -148,6 +350,22 @@ func gopOpts(p Preset) ([]string, error) {
 			"-sc_threshold", "0",
 		}
 	)
+
+	// chunks are cut at source keyframes, not on the GOP grid,
+	// so the first keyframe after the chunk start is forced on it
+	if p.GOPSeconds > 0 {
+		var (
+			period = float64(p.GOPSeconds)
+			phase  = math.Mod(period-math.Mod(p.ChunkStart, period), period)
+		)
+		// the chunk starts on the grid, first frame is a keyframe anyway
+		if phase*fps < 1 {
+			phase = 0
+		}
+		opts = append(opts,
+			"-force_key_frames", fmt.Sprintf("expr:gte(t,%.3f+n_forced*%d)", phase, p.GOPSeconds),
+		)
+	}
 	return opts, err
 }
 
@@ -32,7 +32,28 @@ func Version() (string, error) {
 	return result[1], nil
 }
 
+// HasFilter reports whether ffmpeg is built with the filter, libvmaf, etc.
+func HasFilter(name string) (bool, error) {
+	out, err := exec.Command(bin, "-hide_banner", "-filters").Output()
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		// flags, name, io, description
+		if f := strings.Fields(line); len(f) > 1 && f[1] == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func execute(ctx context.Context, src string, args []string) error {
+	_, err := executeStderr(ctx, src, args)
+	return err
+}
+
+// same as execute, returns stderr for filters reporting results there (loudnorm, etc.)
+func executeStderr(ctx context.Context, src string, args []string) (string, error) {
 	log.WithFields(log.Fields{
 		"mod":  "ffmpeg",
 		"args": strings.Join(args, " "),
@@ -50,8 +71,8 @@ func execute(ctx context.Context, src string, args []string) error {
 			"stdout": cmd.Stdout.(*bytes.Buffer).String(),
 			"stderr": cmd.Stderr.(*bytes.Buffer).String(),
 		}).Error(err)
-		return parseError(cmd.Stderr.(*bytes.Buffer).String(), src, time.Time{})
+		return "", parseError(cmd.Stderr.(*bytes.Buffer).String(), src, time.Time{})
 	}
 
-	return nil
+	return cmd.Stderr.(*bytes.Buffer).String(), nil
 }
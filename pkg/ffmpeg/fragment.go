@@ -20,8 +20,11 @@ func Fragment(ctx context.Context, src, dstDir, fname string, fragSeconds int) (
 			"-hide_banner",
 			"-i", src,
 			"-c", "copy",
-			"-movflags", "+faststart+frag_keyframe+empty_moov+default_base_moof",
-			"-frag_duration", strconv.FormatInt(int64(fragSeconds)*1_000_000, 10),
+			// global_sidx indexes all fragments for DASH on-demand (SegmentBase) addressing,
+			// moov is already at the beginning of the file because of empty_moov
+			"-movflags", "+frag_keyframe+empty_moov+default_base_moof+global_sidx",
+			// GOP can be shorter than a fragment, so it starts at the first keyframe after fragSeconds
+			"-min_frag_duration", strconv.FormatInt(int64(fragSeconds)*1_000_000, 10),
 			"-f", "mp4",
 			out,
 		}
@@ -0,0 +1,123 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/timohahaa/transcoder/pkg/consts"
+)
+
+var ErrNoIdetStats = errors.New("no idet stats in ffmpeg output")
+
+// frames classified by idet multi frame detection
+type Interlace struct {
+	TFF          int
+	BFF          int
+	Progressive  int
+	Undetermined int
+}
+
+// Ratio of interlaced frames to classified ones, 0 if none are classified
+func (i Interlace) Ratio() float64 {
+	var classified = i.TFF + i.BFF + i.Progressive
+	if classified == 0 {
+		return 0
+	}
+	return float64(i.TFF+i.BFF) / float64(classified)
+}
+
+// FieldOrder of the majority of interlaced frames, progressive if there are none
+func (i Interlace) FieldOrder() string {
+	switch {
+	case i.TFF == 0 && i.BFF == 0:
+		return consts.FieldOrderProgressive
+	case i.BFF > i.TFF:
+		return consts.FieldOrderBFF
+	default:
+		return consts.FieldOrderTFF
+	}
+}
+
+// Deinterlace runs before any other filter, so fps and scaling get whole frames
+type Deinterlace struct {
+	Filter     string // consts.DeinterlaceBwdif or consts.DeinterlaceYadif
+	FieldOrder string // consts.FieldOrderTFF or consts.FieldOrderBFF
+	FieldRate  bool   // a frame per field, 50i becomes 50p, 25p otherwise
+}
+
+func (d Deinterlace) filter() string {
+	var mode = "send_frame"
+	if d.FieldRate {
+		mode = "send_field"
+	}
+	// frame flags of broadcast sources are unreliable, so every frame is deinterlaced
+	return fmt.Sprintf("%s=mode=%s:parity=%s:deint=all", d.Filter, mode, d.FieldOrder)
+}
+
+// DetectInterlace runs idet over frames of src starting at start seconds
+func DetectInterlace(ctx context.Context, src string, start float64, frames int) (Interlace, error) {
+	var args = []string{
+		"-hide_banner",
+		"-nostats",
+		"-ss", formatFloat(start),
+		"-i", src,
+		"-map", "0:v:0",
+		"-vf", "idet",
+		"-frames:v", strconv.Itoa(frames),
+		"-an", "-sn", "-dn",
+		"-f", "null",
+		"-",
+	}
+
+	stderr, err := executeStderr(ctx, src, args)
+	if err != nil {
+		return Interlace{}, err
+	}
+
+	return parseIdet(stderr)
+}
+
+// idet prints stats of the whole run on exit,
+// multi frame detection is more reliable than the single frame one:
+//
+//	[Parsed_idet_0 @ 0x5581] Multi frame detection: TFF:  1190 BFF:     0 Progressive:     3 Undetermined:     7
+func parseIdet(stderr string) (Interlace, error) {
+	var (
+		res   Interlace
+		found bool
+	)
+	for _, line := range strings.Split(stderr, "\n") {
+		_, stats, ok := strings.Cut(line, "Multi frame detection:")
+		if !ok {
+			continue
+		}
+
+		var fields = strings.Fields(stats)
+		for i := 0; i+1 < len(fields); i += 2 {
+			n, err := strconv.Atoi(fields[i+1])
+			if err != nil {
+				return Interlace{}, fmt.Errorf("parse idet stats: %v", err)
+			}
+
+			switch fields[i] {
+			case "TFF:":
+				res.TFF = n
+			case "BFF:":
+				res.BFF = n
+			case "Progressive:":
+				res.Progressive = n
+			case "Undetermined:":
+				res.Undetermined = n
+			}
+		}
+		found = true
+	}
+
+	if !found {
+		return Interlace{}, ErrNoIdetStats
+	}
+	return res, nil
+}
@@ -0,0 +1,148 @@
+package ffmpeg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrNoLoudnessStats = errors.New("no loudnorm stats in ffmpeg output")
+
+// EBU R128 targets
+type LoudnessTarget struct {
+	I   float64 // integrated loudness, LUFS
+	TP  float64 // true peak, dBTP
+	LRA float64 // loudness range, LU
+}
+
+// measured by the first loudnorm pass
+type Loudness struct {
+	I      float64
+	TP     float64
+	LRA    float64
+	Thresh float64
+	Offset float64 // gain to reach the target after normalization
+}
+
+// second loudnorm pass: linear gain computed from the measured values,
+// loudnorm falls back to dynamic mode itself if true peak can not be kept linearly
+type Loudnorm struct {
+	Target   LoudnessTarget
+	Measured Loudness
+}
+
+func (l Loudnorm) filter() string {
+	return fmt.Sprintf(
+		"loudnorm=I=%s:TP=%s:LRA=%s"+
+			":measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s"+
+			":linear=true:print_format=summary",
+		formatFloat(l.Target.I),
+		formatFloat(l.Target.TP),
+		formatFloat(l.Target.LRA),
+		formatFloat(l.Measured.I),
+		formatFloat(l.Measured.TP),
+		formatFloat(l.Measured.LRA),
+		formatFloat(l.Measured.Thresh),
+		formatFloat(l.Measured.Offset),
+	)
+}
+
+// MeasureLoudness runs the first loudnorm pass over the whole src
+func MeasureLoudness(ctx context.Context, src string, target LoudnessTarget) (Loudness, error) {
+	return measureLoudness(ctx, src, "", target)
+}
+
+// MeasureDownmixLoudness runs the first loudnorm pass over src downmixed to channels
+// the way renditions are, downmix gains change loudness of the result
+func MeasureDownmixLoudness(
+	ctx context.Context,
+	src string,
+	d Downmix,
+	channels int,
+	target LoudnessTarget,
+) (Loudness, error) {
+	return measureLoudness(ctx, src, d.filter(channels), target)
+}
+
+func measureLoudness(ctx context.Context, src, prefilter string, target LoudnessTarget) (Loudness, error) {
+	var args = []string{
+		"-hide_banner",
+		"-nostats",
+		"-i", src,
+		"-vn",
+		"-af", joinFilters(prefilter, fmt.Sprintf(
+			"loudnorm=I=%s:TP=%s:LRA=%s:print_format=json",
+			formatFloat(target.I),
+			formatFloat(target.TP),
+			formatFloat(target.LRA),
+		)),
+		"-f", "null",
+		"-",
+	}
+
+	stderr, err := executeStderr(ctx, src, args)
+	if err != nil {
+		return Loudness{}, err
+	}
+
+	return parseLoudness(stderr)
+}
+
+// loudnorm prints json stats as the last thing in stderr
+func parseLoudness(stderr string) (Loudness, error) {
+	var (
+		start = strings.LastIndex(stderr, "{")
+		end   = strings.LastIndex(stderr, "}")
+	)
+	if start < 0 || end < start {
+		return Loudness{}, ErrNoLoudnessStats
+	}
+
+	var stats struct {
+		InputI       string `json:"input_i"`
+		InputTP      string `json:"input_tp"`
+		InputLRA     string `json:"input_lra"`
+		InputThresh  string `json:"input_thresh"`
+		TargetOffset string `json:"target_offset"`
+	}
+	if err := json.Unmarshal([]byte(stderr[start:end+1]), &stats); err != nil {
+		return Loudness{}, err
+	}
+
+	var (
+		l    Loudness
+		errs []error
+	)
+	for _, v := range []struct {
+		dst *float64
+		src string
+	}{
+		{&l.I, stats.InputI},
+		{&l.TP, stats.InputTP},
+		{&l.LRA, stats.InputLRA},
+		{&l.Thresh, stats.InputThresh},
+		{&l.Offset, stats.TargetOffset},
+	} {
+		var err error
+		if *v.dst, err = strconv.ParseFloat(v.src, 64); err != nil {
+			errs = append(errs, err)
+		} else if math.IsInf(*v.dst, 0) || math.IsNaN(*v.dst) {
+			errs = append(errs, fmt.Errorf("not finite value %q", v.src))
+		}
+	}
+
+	// silent input: -inf values, nothing to normalize
+	if len(errs) > 0 {
+		return Loudness{}, fmt.Errorf("%w: %v", ErrNoLoudnessStats, errors.Join(errs...))
+	}
+
+	return l, nil
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,170 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// psnr filter reports identical frames as inf
+const maxPSNR = 100
+
+// distorted and reference are brought to the same size and frame rate before scoring
+type QualityOpts struct {
+	Width, Height int          // scoring resolution, usually of the top rendition
+	FPS           string       // of the distorted rendition, reference is converted to it
+	VMAF          bool         // libvmaf is available, psnr and ssim filters are used otherwise
+	LogDir        string       // per-frame logs are written there
+	Tonemap       string       // transfer of HDR reference tone mapped like the distorted one, empty if none
+	Deinterlace   *Deinterlace // of interlaced reference, same as of the distorted one
+	// SDR reference is converted to the matrix and range of the distorted one
+	SourceColorSpace, SourceColorRange, ColorSpace string
+}
+
+// per-frame scores, nil if not measured
+type QualityScores struct {
+	VMAF []float64
+	PSNR []float64 // luma, dB
+	SSIM []float64 // luma
+}
+
+// MeasureQuality scores every frame of dist against ref in a single run
+func MeasureQuality(
+	ctx context.Context,
+	cpuIdx []int,
+	dist, ref string,
+	o QualityOpts,
+) (QualityScores, error) {
+	if err := os.MkdirAll(o.LogDir, os.ModePerm); err != nil {
+		return QualityScores{}, err
+	}
+
+	var (
+		name    = strings.TrimSuffix(filepath.Base(dist), filepath.Ext(dist))
+		vmafLog = filepath.Join(o.LogDir, name+"_vmaf.json")
+		psnrLog = filepath.Join(o.LogDir, name+"_psnr.log")
+		ssimLog = filepath.Join(o.LogDir, name+"_ssim.log")
+		prepare = fmt.Sprintf(
+			"scale=%d:%d:flags=bicubic,format=yuv420p,setsar=1,settb=AVTB,setpts=PTS-STARTPTS",
+			o.Width, o.Height,
+		)
+		refPrepare = "fps=" + o.FPS
+	)
+	if o.Deinterlace != nil {
+		refPrepare = o.Deinterlace.filter() + "," + refPrepare
+	}
+	if o.Tonemap != "" {
+		refPrepare += "," + TonemapFilter(o.Tonemap)
+	} else if convert := ColorConvertOpts(o.SourceColorSpace, o.ColorSpace, o.SourceColorRange); len(convert) > 0 {
+		refPrepare += ",scale=" + strings.Join(convert, ":")
+	}
+	var filter = fmt.Sprintf("[0:v:0]%s[dist];[1:v:0]%s,%s[ref];", prepare, refPrepare, prepare)
+
+	if o.VMAF {
+		filter += fmt.Sprintf(
+			"[dist][ref]libvmaf=log_fmt=json:log_path=%s:n_threads=%d:feature='name=psnr|name=float_ssim'",
+			vmafLog, max(len(cpuIdx), 1),
+		)
+	} else {
+		filter += fmt.Sprintf(
+			"[dist]split[dist1][dist2];[ref]split[ref1][ref2];"+
+				"[dist1][ref1]psnr=stats_file=%s;[dist2][ref2]ssim=stats_file=%s",
+			psnrLog, ssimLog,
+		)
+	}
+
+	var args = []string{
+		"-xerror",
+		"-hide_banner",
+		"-i", dist,
+		"-i", ref,
+		"-filter_complex", filter,
+		"-an", "-sn",
+		"-f", "null",
+		"-",
+	}
+
+	if _, err := scope(ctx, cpuIdx, dist, nil, args, DiscardProgress); err != nil {
+		return QualityScores{}, err
+	}
+
+	if o.VMAF {
+		return parseVMAFLog(vmafLog)
+	}
+
+	psnr, err := parseStatsFile(psnrLog, "psnr_y")
+	if err != nil {
+		return QualityScores{}, err
+	}
+	ssim, err := parseStatsFile(ssimLog, "Y")
+	if err != nil {
+		return QualityScores{}, err
+	}
+	return QualityScores{PSNR: psnr, SSIM: ssim}, nil
+}
+
+func parseVMAFLog(path string) (QualityScores, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return QualityScores{}, err
+	}
+
+	var log struct {
+		Frames []struct {
+			Metrics map[string]float64 `json:"metrics"`
+		} `json:"frames"`
+	}
+	if err := json.Unmarshal(b, &log); err != nil {
+		return QualityScores{}, fmt.Errorf("parse vmaf log: %v", err)
+	}
+
+	var res = QualityScores{
+		VMAF: make([]float64, 0, len(log.Frames)),
+		PSNR: make([]float64, 0, len(log.Frames)),
+		SSIM: make([]float64, 0, len(log.Frames)),
+	}
+	for _, f := range log.Frames {
+		res.VMAF = append(res.VMAF, f.Metrics["vmaf"])
+		res.PSNR = append(res.PSNR, min(f.Metrics["psnr_y"], maxPSNR))
+		res.SSIM = append(res.SSIM, f.Metrics["float_ssim"])
+	}
+	return res, nil
+}
+
+// psnr and ssim filters write a line of key:value pairs per frame
+func parseStatsFile(path, key string) ([]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		res     []float64
+		scanner = bufio.NewScanner(f)
+	)
+	for scanner.Scan() {
+		for _, field := range strings.Fields(scanner.Text()) {
+			k, v, ok := strings.Cut(field, ":")
+			if !ok || k != key {
+				continue
+			}
+
+			score, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %v", filepath.Base(path), err)
+			}
+			if math.IsInf(score, 1) {
+				score = maxPSNR
+			}
+			res = append(res, score)
+		}
+	}
+	return res, scanner.Err()
+}
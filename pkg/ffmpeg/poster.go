@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strconv"
 )
 
 func PosterThumbCPU(
@@ -34,3 +35,26 @@ func PosterThumbCPU(
 	_, err := scope(ctx, cpuIdx, src, nil, args, DiscardProgress)
 	return output, err
 }
+
+// PosterVariant scales a poster down to width keeping aspect ratio,
+// output format is taken from dst extension (jpg, webp)
+func PosterVariant(ctx context.Context, src, dst string, width int) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	var args = []string{
+		"-xerror",
+		"-hide_banner",
+		"-y",
+		"-i", src,
+		"-vf", "scale=" + strconv.Itoa(width) + ":-2",
+		"-frames:v", "1",
+		"-update", "1",
+		"-qscale:v", "2", // jpeg
+		"-quality", "85", // webp
+		dst,
+	}
+
+	return execute(ctx, src, args)
+}
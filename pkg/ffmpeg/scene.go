@@ -0,0 +1,80 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// scene score doesn't need details, frames are downscaled to it
+const sceneDetectHeight = 360
+
+// DetectScenes returns times (seconds, ascending) of frames differing from
+// the previous one by more than threshold (0..1), logPath keeps the raw filter output
+func DetectScenes(ctx context.Context, src, logPath string, threshold float64) ([]float64, error) {
+	if err := os.MkdirAll(filepath.Dir(logPath), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	var args = []string{
+		"-xerror",
+		"-hide_banner",
+		"-i", src,
+		"-map", "0:v:0",
+		"-vf", fmt.Sprintf(
+			"scale=-2:'min(ih,%d)',select='gt(scene,%.3f)',metadata=mode=print:file=%s",
+			sceneDetectHeight, threshold, logPath,
+		),
+		"-fps_mode", "passthrough",
+		"-an", "-sn", "-dn",
+		"-f", "null",
+		"-",
+	}
+
+	if err := execute(ctx, src, args); err != nil {
+		return nil, err
+	}
+
+	return parseSceneLog(logPath)
+}
+
+// metadata filter writes a frame line followed by its metadata:
+//
+//	frame:12   pts:12012   pts_time:12.012
+//	lavfi.scene_score=0.514
+func parseSceneLog(path string) ([]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		res     []float64
+		scanner = bufio.NewScanner(f)
+	)
+	for scanner.Scan() {
+		var line = scanner.Text()
+		if !strings.HasPrefix(line, "frame:") {
+			continue
+		}
+
+		for _, field := range strings.Fields(line) {
+			v, ok := strings.CutPrefix(field, "pts_time:")
+			if !ok {
+				continue
+			}
+
+			t, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %v", filepath.Base(path), err)
+			}
+			res = append(res, t)
+		}
+	}
+	return res, scanner.Err()
+}
@@ -0,0 +1,70 @@
+package ffmpeg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+)
+
+const (
+	SegmentInitName    = "init.mp4"
+	SegmentNamePattern = "seg_%05d.m4s"
+	SegmentStartNumber = 1
+
+	// playlist written by hls muxer, we generate our own manifests
+	segmentPlaylist = "ffmpeg.m3u8"
+)
+
+type Segments struct {
+	Init  string
+	Media []string // in playback order
+}
+
+// Segment packages src as CMAF: dstDir/init.mp4 + dstDir/seg_NNNNN.m4s.
+// Segments are cut on the first keyframe after every segSeconds,
+// so renditions encoded with the same fixed GOP get aligned boundaries.
+func Segment(ctx context.Context, src, dstDir string, segSeconds int) (*Segments, error) {
+	if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	var (
+		playlist = filepath.Join(dstDir, segmentPlaylist)
+		args     = []string{
+			"-xerror",
+			"-hide_banner",
+			"-i", src,
+			"-c", "copy",
+			"-f", "hls",
+			"-hls_time", strconv.Itoa(segSeconds),
+			"-hls_playlist_type", "vod",
+			"-hls_segment_type", "fmp4",
+			"-hls_fmp4_init_filename", SegmentInitName,
+			"-hls_segment_filename", filepath.Join(dstDir, SegmentNamePattern),
+			"-start_number", strconv.Itoa(SegmentStartNumber),
+			playlist,
+		}
+	)
+
+	if err := execute(ctx, src, args); err != nil {
+		return nil, err
+	}
+
+	if err := os.Remove(playlist); err != nil {
+		return nil, err
+	}
+
+	media, err := filepath.Glob(filepath.Join(dstDir, "seg_*.m4s"))
+	if err != nil {
+		return nil, err
+	}
+	// zero padded numbers, lexical order is playback order
+	slices.Sort(media)
+
+	return &Segments{
+		Init:  filepath.Join(dstDir, SegmentInitName),
+		Media: media,
+	}, nil
+}
@@ -16,11 +16,36 @@ type Chunk struct {
 	Num  int
 }
 
+// Split cuts the source into chunks of duration seconds, at the nearest keyframes
 func Split(
 	ctx context.Context,
 	srcFile, dstDir string,
 	duration int,
 	segmentFormat string,
+) ([]Chunk, error) {
+	return split(ctx, srcFile, dstDir, segmentFormat, "-segment_time", strconv.Itoa(duration))
+}
+
+// SplitAt cuts the source at times (seconds, ascending), every cut is moved
+// to the first keyframe after it, so keyframe times are kept in full precision
+func SplitAt(
+	ctx context.Context,
+	srcFile, dstDir string,
+	times []float64,
+	segmentFormat string,
+) ([]Chunk, error) {
+	var points = make([]string, 0, len(times))
+	for _, t := range times {
+		points = append(points, strconv.FormatFloat(t, 'f', 6, 64))
+	}
+	return split(ctx, srcFile, dstDir, segmentFormat, "-segment_times", strings.Join(points, ","))
+}
+
+func split(
+	ctx context.Context,
+	srcFile, dstDir string,
+	segmentFormat string,
+	segmentArgs ...string,
 ) ([]Chunk, error) {
 	if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
 		return nil, err
@@ -33,11 +58,13 @@ func Split(
 		"-c", "copy",
 		"-an",
 		"-f", "segment", // https://ffmpeg.org/ffmpeg-formats.html#Options-31
-		"-segment_time", strconv.Itoa(duration),
+	}
+	args = append(args, segmentArgs...)
+	args = append(args,
 		"-segment_format", segmentFormat,
 		"-reset_timestamps", "1",
 		filepath.Join(dstDir, "chunk_%03d."+segmentFormat),
-	}
+	)
 
 	if err := execute(ctx, srcFile, args); err != nil {
 		return nil, err
@@ -58,7 +85,7 @@ func Split(
 			return nil, err
 		}
 
-		chunkNum, err := getChunkNum(e.Name())
+		chunkNum, err := ChunkNum(e.Name())
 		if err != nil {
 			return nil, err
 		}
@@ -73,7 +100,8 @@ func Split(
 	return chunks, nil
 }
 
-func getChunkNum(filename string) (int, error) {
+// chunk number from file name: chunk_007.mp4 -> 7
+func ChunkNum(filename string) (int, error) {
 	parts := strings.Split(filename, "_")
 	if len(parts) != 2 {
 		return 0, fmt.Errorf("invalid chunk name format: %v", filename)
@@ -49,7 +49,7 @@ func makeChunkList(dstFolder, fname string, chunkFiles []string) (string, error)
 	)
 
 	for _, file := range chunkFiles {
-		chunkNum, err := getChunkNum(filepath.Base(file))
+		chunkNum, err := ChunkNum(filepath.Base(file))
 		if err != nil {
 			return "", err
 		}
@@ -0,0 +1,61 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/timohahaa/transcoder/pkg/ffprobe"
+)
+
+var ErrNoSubtitles = errors.New("no text subtitles")
+
+// ExtractSubtitles converts every text subtitle stream to WebVTT: dstDir/subtitle_N.vtt,
+// files are in the order of info.GetTextSubtitles()
+func ExtractSubtitles(ctx context.Context, info *ffprobe.Info, srcFile, dstDir string) ([]string, error) {
+	if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	if info == nil {
+		var err error
+		info, err = ffprobe.GetInfo(ctx, srcFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var subtitles = info.GetTextSubtitles()
+	if len(subtitles) == 0 {
+		return nil, ErrNoSubtitles
+	}
+
+	var (
+		subtitleFiles = make([]string, 0, len(subtitles))
+		subtitleMap   = make([]string, 0, len(subtitles))
+	)
+	for i, s := range subtitles {
+		var subtitlePath = filepath.Join(dstDir, fmt.Sprintf("subtitle_%d.vtt", i))
+		subtitleMap = append(
+			subtitleMap,
+			"-map", fmt.Sprintf("0:%d", s.Index),
+			"-c:s", "webvtt",
+			"-f", "webvtt",
+			subtitlePath,
+		)
+		subtitleFiles = append(subtitleFiles, subtitlePath)
+	}
+
+	var args = append([]string{
+		"-xerror",
+		"-hide_banner",
+		"-y",
+		"-i", srcFile,
+	}, subtitleMap...)
+	if err := execute(ctx, srcFile, args); err != nil {
+		return nil, err
+	}
+	return subtitleFiles, nil
+}
@@ -0,0 +1,49 @@
+package ffmpeg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ThumbnailStripCPU takes count frames every interval seconds starting at offset
+// and tiles them into a single horizontal strip: dst/strip.jpg
+func ThumbnailStripCPU(
+	ctx context.Context,
+	cpuIdx []int,
+	src, dst string,
+	offset, interval float64,
+	count, width int,
+) (string, error) {
+	if err := os.MkdirAll(dst, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	var (
+		output = filepath.Join(dst, "strip.jpg")
+		filter = fmt.Sprintf(
+			"fps=1/%s,scale=%d:-2,tile=%dx1",
+			strconv.FormatFloat(interval, 'f', -1, 64),
+			width,
+			count,
+		)
+		args = []string{
+			"-xerror",
+			"-hide_banner",
+			"-y",
+			"-ss", strconv.FormatFloat(offset, 'f', 3, 64),
+			"-i", src,
+			"-an",
+			"-vf", filter,
+			"-frames:v", "1",
+			"-update", "1",
+			"-qscale:v", "4",
+			output,
+		}
+	)
+
+	_, err := scope(ctx, cpuIdx, src, nil, args, DiscardProgress)
+	return output, err
+}
@@ -0,0 +1,38 @@
+package ffprobe
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// FirstFrameSideData returns side data of the first video frame, HEVC and AV1 sources
+// carry HDR metadata in the bitstream, so it is not in stream side data of most containers
+func FirstFrameSideData(ctx context.Context, path string) ([]SideData, error) {
+	args := []string{
+		"-hide_banner",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-read_intervals", "%+#1",
+		"-show_entries", "frame=side_data_list",
+		"-print_format", "json",
+		"-i", path,
+	}
+	out, err := execute(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Frames []struct {
+			SideDataList []SideData `json:"side_data_list"`
+		} `json:"frames"`
+	}
+	if err := json.Unmarshal(out, &res); err != nil {
+		return nil, err
+	}
+
+	if len(res.Frames) == 0 {
+		return nil, nil
+	}
+	return res.Frames[0].SideDataList, nil
+}
@@ -0,0 +1,52 @@
+package ffprobe
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Keyframes returns presentation timestamps (seconds) of the first video stream keyframes.
+// Only packet flags are read, no decoding needed.
+func Keyframes(ctx context.Context, path string) ([]float64, error) {
+	args := []string{
+		"-hide_banner",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "packet=pts_time,flags",
+		"-print_format", "json",
+		"-i", path,
+	}
+	out, err := execute(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Packets []struct {
+			PtsTime string `json:"pts_time"`
+			Flags   string `json:"flags"`
+		} `json:"packets"`
+	}
+	if err := json.Unmarshal(out, &res); err != nil {
+		return nil, err
+	}
+
+	var keyframes []float64
+	for _, p := range res.Packets {
+		if !strings.Contains(p.Flags, "K") {
+			continue
+		}
+		pts, err := strconv.ParseFloat(p.PtsTime, 64)
+		if err != nil {
+			continue // N/A
+		}
+		keyframes = append(keyframes, pts)
+	}
+	// packets are in decoding order
+	slices.Sort(keyframes)
+
+	return keyframes, nil
+}
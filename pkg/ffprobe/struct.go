@@ -73,6 +73,22 @@ type StreamTags struct {
 type SideData struct {
 	Type     string `json:"side_data_type"`
 	Rotation int    `json:"rotation"`
+
+	// mastering display metadata, rationals like 34000/50000
+	RedX         string `json:"red_x"`
+	RedY         string `json:"red_y"`
+	GreenX       string `json:"green_x"`
+	GreenY       string `json:"green_y"`
+	BlueX        string `json:"blue_x"`
+	BlueY        string `json:"blue_y"`
+	WhitePointX  string `json:"white_point_x"`
+	WhitePointY  string `json:"white_point_y"`
+	MinLuminance string `json:"min_luminance"`
+	MaxLuminance string `json:"max_luminance"`
+
+	// content light level metadata, cd/m2
+	MaxContent int `json:"max_content"`
+	MaxAverage int `json:"max_average"`
 }
 
 type Format struct {
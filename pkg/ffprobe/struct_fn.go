@@ -92,6 +92,42 @@ func (info Info) GetAllVideos() []Stream {
 	return a
 }
 
+func (info Info) GetAllSubtitles() []Stream {
+	var a []Stream
+	for _, s := range info.Streams {
+		if s.CodecType == consts.CodecTypeSubtitle {
+			a = append(a, s)
+		}
+	}
+	return a
+}
+
+// text subtitles can be converted to WebVTT, bitmap ones (PGS, DVB, DVD) can not
+func (info Info) GetTextSubtitles() []Stream {
+	var a []Stream
+	for _, s := range info.GetAllSubtitles() {
+		if s.IsTextSubtitle() {
+			a = append(a, s)
+		}
+	}
+	return a
+}
+
+func (s Stream) IsTextSubtitle() bool {
+	switch s.CodecName {
+	case
+		consts.CodecSubRip,
+		consts.CodecASS,
+		consts.CodecSSA,
+		consts.CodecMovText,
+		consts.CodecWebVTT,
+		consts.CodecText:
+		return true
+	default:
+		return false
+	}
+}
+
 func (s Stream) IsPicture() bool {
 	switch {
 	case
@@ -216,7 +252,13 @@ func (s Stream) GetQuality() string {
 	w, h := s.GetRenderResolution()
 	q := min(h, w)
 
-	if 0 < q && q <= 360 {
+	if 0 < q && q <= 144 {
+		return consts.Q144p
+	}
+	if 144 < q && q <= 240 {
+		return consts.Q240p
+	}
+	if 240 < q && q <= 360 {
 		return consts.Q360p
 	}
 	if 360 < q && q <= 480 {
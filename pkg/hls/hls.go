@@ -0,0 +1,268 @@
+package hls
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// fMP4 segments require at least version 7
+// see https://datatracker.ietf.org/doc/html/rfc8216#section-7
+const version = 7
+
+const (
+	MediaTypeAudio     = "AUDIO"
+	MediaTypeSubtitles = "SUBTITLES"
+
+	PlaylistTypeVOD = "VOD"
+
+	KeyMethodSampleAES    = "SAMPLE-AES"     // cbcs
+	KeyMethodSampleAESCTR = "SAMPLE-AES-CTR" // cenc
+
+	KeyFormatIdentity = "identity"
+)
+
+type (
+	MasterPlaylist struct {
+		IndependentSegments bool
+		Media               []Media
+		Variants            []Variant
+	}
+
+	// EXT-X-MEDIA
+	Media struct {
+		Type       string
+		GroupID    string
+		Name       string
+		Language   string
+		Default    bool
+		Autoselect bool
+		Forced     bool // subtitles only
+		Channels   string
+		URI        string
+	}
+
+	// EXT-X-STREAM-INF
+	Variant struct {
+		Bandwidth        int64
+		AverageBandwidth int64
+		Codecs           []string
+		Width            int
+		Height           int
+		FrameRate        float64
+		VideoRange       string // SDR, PQ or HLG, not written if empty
+		Audio            string // audio group id
+		Subtitles        string // subtitles group id
+		URI              string
+	}
+
+	MediaPlaylist struct {
+		PlaylistType        string
+		IndependentSegments bool
+		Keys                []Key
+		Map                 Map
+		Segments            []Segment
+	}
+
+	// EXT-X-KEY
+	Key struct {
+		Method            string
+		URI               string
+		IV                []byte
+		KeyFormat         string
+		KeyFormatVersions string
+	}
+
+	// EXT-X-MAP
+	Map struct {
+		URI       string
+		ByteRange *ByteRange
+	}
+
+	Segment struct {
+		Duration  float64
+		URI       string
+		ByteRange *ByteRange
+	}
+
+	ByteRange struct {
+		Length int64
+		Offset int64
+	}
+)
+
+func (b ByteRange) String() string {
+	return fmt.Sprintf("%d@%d", b.Length, b.Offset)
+}
+
+func (p MasterPlaylist) Encode(w io.Writer) error {
+	var sb strings.Builder
+
+	sb.WriteString("#EXTM3U\n")
+	fmt.Fprintf(&sb, "#EXT-X-VERSION:%d\n", version)
+	if p.IndependentSegments {
+		sb.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+	}
+
+	if len(p.Media) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, m := range p.Media {
+		var attrs = []string{
+			"TYPE=" + m.Type,
+			"GROUP-ID=" + quote(m.GroupID),
+			"NAME=" + quote(m.Name),
+		}
+		if m.Language != "" {
+			attrs = append(attrs, "LANGUAGE="+quote(m.Language))
+		}
+		attrs = append(attrs,
+			"DEFAULT="+yesNo(m.Default),
+			"AUTOSELECT="+yesNo(m.Autoselect),
+		)
+		if m.Forced {
+			attrs = append(attrs, "FORCED=YES")
+		}
+		if m.Channels != "" {
+			attrs = append(attrs, "CHANNELS="+quote(m.Channels))
+		}
+		attrs = append(attrs, "URI="+quote(m.URI))
+
+		fmt.Fprintf(&sb, "#EXT-X-MEDIA:%s\n", strings.Join(attrs, ","))
+	}
+
+	for _, v := range p.Variants {
+		var attrs = []string{
+			"BANDWIDTH=" + strconv.FormatInt(v.Bandwidth, 10),
+		}
+		if v.AverageBandwidth > 0 {
+			attrs = append(attrs, "AVERAGE-BANDWIDTH="+strconv.FormatInt(v.AverageBandwidth, 10))
+		}
+		if len(v.Codecs) > 0 {
+			attrs = append(attrs, "CODECS="+quote(strings.Join(v.Codecs, ",")))
+		}
+		if v.Width > 0 && v.Height > 0 {
+			attrs = append(attrs, fmt.Sprintf("RESOLUTION=%dx%d", v.Width, v.Height))
+		}
+		if v.FrameRate > 0 {
+			attrs = append(attrs, "FRAME-RATE="+strconv.FormatFloat(v.FrameRate, 'f', 3, 64))
+		}
+		if v.VideoRange != "" {
+			attrs = append(attrs, "VIDEO-RANGE="+v.VideoRange)
+		}
+		if v.Audio != "" {
+			attrs = append(attrs, "AUDIO="+quote(v.Audio))
+		}
+		if v.Subtitles != "" {
+			attrs = append(attrs, "SUBTITLES="+quote(v.Subtitles))
+		}
+
+		fmt.Fprintf(&sb, "\n#EXT-X-STREAM-INF:%s\n%s\n", strings.Join(attrs, ","), v.URI)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (p MediaPlaylist) Encode(w io.Writer) error {
+	var (
+		sb             strings.Builder
+		targetDuration float64
+	)
+
+	for _, s := range p.Segments {
+		targetDuration = max(targetDuration, s.Duration)
+	}
+
+	sb.WriteString("#EXTM3U\n")
+	fmt.Fprintf(&sb, "#EXT-X-VERSION:%d\n", version)
+	fmt.Fprintf(&sb, "#EXT-X-TARGETDURATION:%d\n", int64(math.Ceil(targetDuration)))
+	sb.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n")
+	if p.PlaylistType != "" {
+		fmt.Fprintf(&sb, "#EXT-X-PLAYLIST-TYPE:%s\n", p.PlaylistType)
+	}
+	if p.IndependentSegments {
+		sb.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+	}
+
+	for _, k := range p.Keys {
+		var attrs = []string{"METHOD=" + k.Method}
+		if k.URI != "" {
+			attrs = append(attrs, "URI="+quote(k.URI))
+		}
+		if len(k.IV) > 0 {
+			attrs = append(attrs, "IV=0x"+hex.EncodeToString(k.IV))
+		}
+		if k.KeyFormat != "" {
+			attrs = append(attrs, "KEYFORMAT="+quote(k.KeyFormat))
+		}
+		if k.KeyFormatVersions != "" {
+			attrs = append(attrs, "KEYFORMATVERSIONS="+quote(k.KeyFormatVersions))
+		}
+		fmt.Fprintf(&sb, "#EXT-X-KEY:%s\n", strings.Join(attrs, ","))
+	}
+
+	if p.Map.URI != "" {
+		var attrs = []string{"URI=" + quote(p.Map.URI)}
+		if p.Map.ByteRange != nil {
+			attrs = append(attrs, "BYTERANGE="+quote(p.Map.ByteRange.String()))
+		}
+		fmt.Fprintf(&sb, "#EXT-X-MAP:%s\n", strings.Join(attrs, ","))
+	}
+
+	for _, s := range p.Segments {
+		fmt.Fprintf(&sb, "#EXTINF:%s,\n", strconv.FormatFloat(s.Duration, 'f', 6, 64))
+		if s.ByteRange != nil {
+			fmt.Fprintf(&sb, "#EXT-X-BYTERANGE:%s\n", s.ByteRange)
+		}
+		fmt.Fprintf(&sb, "%s\n", s.URI)
+	}
+
+	if p.PlaylistType == PlaylistTypeVOD {
+		sb.WriteString("#EXT-X-ENDLIST\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+type encoder interface {
+	Encode(w io.Writer) error
+}
+
+// writes playlist to dstDir/fname, returns full path
+func Write(p encoder, dstDir, fname string) (string, error) {
+	if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	var out = filepath.Join(dstDir, fname)
+
+	f, err := os.Create(out)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := p.Encode(f); err != nil {
+		return "", err
+	}
+
+	return out, f.Sync()
+}
+
+func quote(s string) string {
+	return `"` + s + `"`
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "YES"
+	}
+	return "NO"
+}
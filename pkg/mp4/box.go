@@ -0,0 +1,99 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrInvalidBox = errors.New("invalid box")
+
+type box struct {
+	Type   string
+	Offset int64 // offset of the box header in the file
+	Size   int64 // full box size, header included
+	Header int64 // header size (8 or 16 bytes)
+}
+
+func (b box) payloadOffset() int64 { return b.Offset + b.Header }
+func (b box) payloadSize() int64   { return b.Size - b.Header }
+
+// reads box header at the given offset
+// limit is the offset where the parent box (or the file) ends
+func readBox(r io.ReaderAt, offset, limit int64) (box, error) {
+	var hdr [16]byte
+	if _, err := r.ReadAt(hdr[:8], offset); err != nil {
+		return box{}, err
+	}
+
+	var b = box{
+		Type:   string(hdr[4:8]),
+		Offset: offset,
+		Size:   int64(binary.BigEndian.Uint32(hdr[0:4])),
+		Header: 8,
+	}
+
+	switch b.Size {
+	case 0: // box extends to the end of the file
+		b.Size = limit - offset
+	case 1: // 64-bit size
+		if _, err := r.ReadAt(hdr[8:16], offset+8); err != nil {
+			return box{}, err
+		}
+		b.Size = int64(binary.BigEndian.Uint64(hdr[8:16]))
+		b.Header = 16
+	}
+
+	if b.Size < b.Header || offset+b.Size > limit {
+		return box{}, fmt.Errorf("%w: %q at %d", ErrInvalidBox, b.Type, offset)
+	}
+
+	return b, nil
+}
+
+// returns all boxes located between start and end offsets
+func readBoxes(r io.ReaderAt, start, end int64) ([]box, error) {
+	var boxes []box
+	for offset := start; offset+8 <= end; {
+		b, err := readBox(r, offset, end)
+		if err != nil {
+			return nil, err
+		}
+		boxes = append(boxes, b)
+		offset += b.Size
+	}
+	return boxes, nil
+}
+
+func children(r io.ReaderAt, parent box) ([]box, error) {
+	return readBoxes(r, parent.payloadOffset(), parent.Offset+parent.Size)
+}
+
+// finds a box by path, for example: "trak", "mdia", "mdhd"
+func find(r io.ReaderAt, parent box, path ...string) (box, bool) {
+	if len(path) == 0 {
+		return parent, true
+	}
+
+	boxes, err := children(r, parent)
+	if err != nil {
+		return box{}, false
+	}
+
+	for _, b := range boxes {
+		if b.Type == path[0] {
+			return find(r, b, path[1:]...)
+		}
+	}
+
+	return box{}, false
+}
+
+func readPayload(r io.ReaderAt, b box) ([]byte, error) {
+	var buf = make([]byte, b.payloadSize())
+	if _, err := r.ReadAt(buf, b.payloadOffset()); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
@@ -0,0 +1,271 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+	"strings"
+)
+
+const (
+	visualSampleEntrySize = 78
+	audioSampleEntrySize  = 28
+)
+
+// returns RFC 6381 codec string of the first sample entry,
+// empty string if codec is unknown
+func readCodec(r io.ReaderAt, moov box) string {
+	stsd, ok := find(r, moov, "trak", "mdia", "minf", "stbl", "stsd")
+	if !ok {
+		return ""
+	}
+
+	// full box header (4) + entry count (4)
+	entries, err := readBoxes(r, stsd.payloadOffset()+8, stsd.Offset+stsd.Size)
+	if err != nil || len(entries) == 0 {
+		return ""
+	}
+
+	var (
+		entry = entries[0]
+		typ   = entry.Type
+	)
+
+	// encrypted entries keep original format in sinf/frma
+	switch typ {
+	case "encv":
+		typ = originalFormat(r, entry, visualSampleEntrySize)
+	case "enca":
+		typ = originalFormat(r, entry, audioSampleEntrySize)
+	}
+
+	switch typ {
+	case "avc1", "avc3":
+		return avcCodec(r, entry, typ)
+	case "hvc1", "hev1":
+		return hevcCodec(r, entry, typ)
+	case "av01":
+		return av1Codec(r, entry, typ)
+	case "mp4a":
+		return mp4aCodec(r, entry, typ)
+	case "Opus":
+		return "opus"
+	case "ac-3", "ec-3":
+		return typ
+	default:
+		return ""
+	}
+}
+
+func originalFormat(r io.ReaderAt, entry box, entrySize int64) string {
+	for _, b := range sampleEntryChildren(r, entry, entrySize) {
+		if b.Type != "sinf" {
+			continue
+		}
+
+		frma, ok := find(r, b, "frma")
+		if !ok {
+			return ""
+		}
+
+		p, err := readPayload(r, frma)
+		if err != nil || len(p) < 4 {
+			return ""
+		}
+		return string(p[:4])
+	}
+	return ""
+}
+
+func sampleEntryChildren(r io.ReaderAt, entry box, entrySize int64) []box {
+	boxes, err := readBoxes(r, entry.payloadOffset()+entrySize, entry.Offset+entry.Size)
+	if err != nil {
+		return nil
+	}
+	return boxes
+}
+
+func avcCodec(r io.ReaderAt, entry box, typ string) string {
+	for _, b := range sampleEntryChildren(r, entry, visualSampleEntrySize) {
+		if b.Type != "avcC" {
+			continue
+		}
+
+		p, err := readPayload(r, b)
+		if err != nil || len(p) < 4 {
+			return typ
+		}
+
+		// profile, profile compatibility, level
+		return fmt.Sprintf("%s.%02X%02X%02X", typ, p[1], p[2], p[3])
+	}
+	return typ
+}
+
+// see ISO/IEC 14496-15 Annex E.3
+func hevcCodec(r io.ReaderAt, entry box, typ string) string {
+	for _, b := range sampleEntryChildren(r, entry, visualSampleEntrySize) {
+		if b.Type != "hvcC" {
+			continue
+		}
+
+		p, err := readPayload(r, b)
+		if err != nil || len(p) < 13 {
+			return typ
+		}
+
+		var (
+			space   = p[1] >> 6
+			tier    = "L"
+			profile = p[1] & 0x1F
+			compat  = bits.Reverse32(binary.BigEndian.Uint32(p[2:6]))
+			level   = p[12]
+			codec   strings.Builder
+		)
+		if p[1]&0x20 != 0 {
+			tier = "H"
+		}
+
+		codec.WriteString(typ + ".")
+		if space > 0 {
+			codec.WriteByte('A' + space - 1)
+		}
+		fmt.Fprintf(&codec, "%d.%X.%s%d", profile, compat, tier, level)
+
+		// constraint flags, trailing zero bytes are omitted
+		var constraints = p[6:12]
+		for len(constraints) > 0 && constraints[len(constraints)-1] == 0 {
+			constraints = constraints[:len(constraints)-1]
+		}
+		for _, c := range constraints {
+			fmt.Fprintf(&codec, ".%X", c)
+		}
+
+		return codec.String()
+	}
+	return typ
+}
+
+// see AV1 Codec ISO Media File Format Binding, codecs parameter string
+func av1Codec(r io.ReaderAt, entry box, typ string) string {
+	for _, b := range sampleEntryChildren(r, entry, visualSampleEntrySize) {
+		if b.Type != "av1C" {
+			continue
+		}
+
+		p, err := readPayload(r, b)
+		if err != nil || len(p) < 3 {
+			return typ
+		}
+
+		var (
+			profile  = p[1] >> 5
+			level    = p[1] & 0x1F
+			tier     = "M"
+			bitDepth = 8
+		)
+		if p[2]&0x80 != 0 {
+			tier = "H"
+		}
+		switch {
+		case p[2]&0x40 != 0 && p[2]&0x20 != 0:
+			bitDepth = 12
+		case p[2]&0x40 != 0:
+			bitDepth = 10
+		}
+
+		return fmt.Sprintf("%s.%d.%02d%s.%02d", typ, profile, level, tier, bitDepth)
+	}
+	return typ
+}
+
+func mp4aCodec(r io.ReaderAt, entry box, typ string) string {
+	for _, b := range sampleEntryChildren(r, entry, audioSampleEntrySize) {
+		if b.Type != "esds" {
+			continue
+		}
+
+		p, err := readPayload(r, b)
+		if err != nil || len(p) < 4 {
+			return typ
+		}
+
+		oti, aot, ok := parseESDS(p[4:]) // skip full box header
+		if !ok {
+			return typ
+		}
+		if aot == 0 {
+			return fmt.Sprintf("%s.%02x", typ, oti)
+		}
+		return fmt.Sprintf("%s.%02x.%d", typ, oti, aot)
+	}
+	return typ
+}
+
+const (
+	esDescrTag            = 0x03
+	decoderConfigDescrTag = 0x04
+	decSpecificInfoTag    = 0x05
+)
+
+// returns object type indication and audio object type
+// see ISO/IEC 14496-1 (descriptors) and ISO/IEC 14496-3 (AudioSpecificConfig)
+func parseESDS(p []byte) (oti byte, aot int, ok bool) {
+	for pos := 0; pos < len(p); {
+		var tag = p[pos]
+		pos++
+
+		// expandable size: up to 4 bytes, 7 bits each
+		var size int
+		for range 4 {
+			if pos >= len(p) {
+				return 0, 0, false
+			}
+			b := p[pos]
+			pos++
+			size = size<<7 | int(b&0x7F)
+			if b&0x80 == 0 {
+				break
+			}
+		}
+
+		switch tag {
+		case esDescrTag:
+			if pos+3 > len(p) {
+				return 0, 0, false
+			}
+			// ES_ID + flags
+			var flags = p[pos+2]
+			pos += 3
+			if flags&0x80 != 0 { // streamDependenceFlag
+				pos += 2
+			}
+			if flags&0x40 != 0 && pos < len(p) { // URL_Flag
+				pos += int(p[pos]) + 1
+			}
+			if flags&0x20 != 0 { // OCRstreamFlag
+				pos += 2
+			}
+		case decoderConfigDescrTag:
+			if pos+13 > len(p) {
+				return 0, 0, false
+			}
+			oti, ok = p[pos], true
+			pos += 13 // oti, stream type, buffer size, max and avg bitrates
+		case decSpecificInfoTag:
+			if pos+2 > len(p) {
+				return oti, 0, ok
+			}
+			var asc = binary.BigEndian.Uint16(p[pos : pos+2])
+			aot = int(asc >> 11)
+			if aot == 31 { // escape value
+				aot = 32 + int(asc>>5&0x3F)
+			}
+			return oti, aot, ok
+		default:
+			pos += size
+		}
+	}
+	return oti, aot, ok
+}
@@ -0,0 +1,331 @@
+package mp4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+type (
+	ByteRange struct {
+		Offset int64
+		Length int64
+	}
+
+	// Fragment is a single moof+mdat pair
+	Fragment struct {
+		ByteRange
+		DecodeTime uint64 // in track timescale
+		Duration   uint64 // in track timescale
+	}
+
+	// Index describes the layout of a fragmented mp4 file.
+	// Files are expected to contain a single track,
+	// which is always the case for our renditions and audio tracks.
+	Index struct {
+		Init      ByteRange // ftyp + moov
+		Sidx      *ByteRange
+		Timescale uint32
+		Codec     string // RFC 6381 codec string
+		PSSH      []PSSH // encrypted files only
+		Fragments []Fragment
+	}
+)
+
+func (r ByteRange) End() int64 { return r.Offset + r.Length }
+
+func (i Index) Seconds(units uint64) float64 {
+	if i.Timescale == 0 {
+		return 0
+	}
+	return float64(units) / float64(i.Timescale)
+}
+
+func (i Index) Duration() float64 {
+	var total uint64
+	for _, f := range i.Fragments {
+		total += f.Duration
+	}
+	return i.Seconds(total)
+}
+
+func (i Index) Size() int64 {
+	var size = i.Init.Length
+	for _, f := range i.Fragments {
+		size += f.Length
+	}
+	return size
+}
+
+func ReadIndex(path string) (*Index, error) {
+	var ir = &indexReader{idx: &Index{}}
+
+	if err := readFile(path, ir.read); err != nil {
+		return nil, err
+	}
+	if !ir.hasMoov {
+		return nil, fmt.Errorf("%w: no moov found", ErrInvalidBox)
+	}
+
+	return ir.idx, nil
+}
+
+// ReadSegmentedIndex builds index of CMAF output split into
+// separate init segment and media segments.
+// Init range covers the whole init file, every fragment covers
+// the whole media segment file with the same position in segments.
+func ReadSegmentedIndex(initPath string, segments []string) (*Index, error) {
+	var ir = &indexReader{idx: &Index{}}
+
+	if err := readFile(initPath, ir.read); err != nil {
+		return nil, err
+	}
+	if !ir.hasMoov {
+		return nil, fmt.Errorf("%w: no moov found in %s", ErrInvalidBox, initPath)
+	}
+
+	var fragments = make([]Fragment, 0, len(segments))
+	for _, path := range segments {
+		var (
+			seg = Fragment{}
+			err = readFile(path, func(r io.ReaderAt, size int64) error {
+				ir.idx.Fragments = ir.idx.Fragments[:0]
+				if err := ir.read(r, size); err != nil {
+					return err
+				}
+				if len(ir.idx.Fragments) == 0 {
+					return fmt.Errorf("%w: no moof found in %s", ErrInvalidBox, path)
+				}
+
+				seg.Length = size
+				seg.DecodeTime = ir.idx.Fragments[0].DecodeTime
+				for _, f := range ir.idx.Fragments {
+					seg.Duration += f.Duration
+				}
+				return nil
+			})
+		)
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, seg)
+	}
+
+	// per-segment sidx boxes (if any) are useless for the whole track
+	ir.idx.Sidx = nil
+	ir.idx.Fragments = fragments
+
+	return ir.idx, nil
+}
+
+func readFile(path string, fn func(r io.ReaderAt, size int64) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	return fn(f, s.Size())
+}
+
+// accumulates index across one or more files of the same track
+type indexReader struct {
+	idx                   *Index
+	hasMoov               bool
+	defaultSampleDuration uint32
+}
+
+func (ir *indexReader) read(r io.ReaderAt, size int64) error {
+	boxes, err := readBoxes(r, 0, size)
+	if err != nil {
+		return err
+	}
+
+	var currFragment *Fragment
+
+	for _, b := range boxes {
+		switch b.Type {
+		case "moov":
+			ir.hasMoov = true
+			ir.idx.Init = ByteRange{Offset: 0, Length: b.Offset + b.Size}
+
+			if ir.idx.Timescale, err = readTimescale(r, b); err != nil {
+				return err
+			}
+			ir.defaultSampleDuration = readTrexDefaultDuration(r, b)
+			ir.idx.Codec = readCodec(r, b)
+			ir.idx.PSSH = readPSSH(r, b)
+		case "sidx":
+			ir.idx.Sidx = &ByteRange{Offset: b.Offset, Length: b.Size}
+		case "moof":
+			if !ir.hasMoov {
+				return fmt.Errorf("%w: moof before moov", ErrInvalidBox)
+			}
+
+			frag, err := readFragment(r, b, ir.defaultSampleDuration)
+			if err != nil {
+				return err
+			}
+			ir.idx.Fragments = append(ir.idx.Fragments, frag)
+			currFragment = &ir.idx.Fragments[len(ir.idx.Fragments)-1]
+		case "mdat":
+			if currFragment != nil {
+				currFragment.Length = b.Offset + b.Size - currFragment.Offset
+			}
+		}
+	}
+
+	return nil
+}
+
+func readTimescale(r io.ReaderAt, moov box) (uint32, error) {
+	mdhd, ok := find(r, moov, "trak", "mdia", "mdhd")
+	if !ok {
+		return 0, fmt.Errorf("%w: no mdhd found", ErrInvalidBox)
+	}
+
+	p, err := readPayload(r, mdhd)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case len(p) >= 24 && p[0] == 1: // version 1 - 64-bit times
+		return binary.BigEndian.Uint32(p[20:24]), nil
+	case len(p) >= 16:
+		return binary.BigEndian.Uint32(p[12:16]), nil
+	default:
+		return 0, fmt.Errorf("%w: mdhd is too short", ErrInvalidBox)
+	}
+}
+
+func readTrexDefaultDuration(r io.ReaderAt, moov box) uint32 {
+	trex, ok := find(r, moov, "mvex", "trex")
+	if !ok {
+		return 0
+	}
+
+	p, err := readPayload(r, trex)
+	if err != nil || len(p) < 16 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint32(p[12:16])
+}
+
+const (
+	tfhdBaseDataOffset       = 0x01
+	tfhdSampleDescIndex      = 0x02
+	tfhdDefaultSampleDur     = 0x08
+	trunDataOffset           = 0x01
+	trunFirstSampleFlags     = 0x04
+	trunSampleDuration       = 0x100
+	trunSampleSize           = 0x200
+	trunSampleFlags          = 0x400
+	trunSampleCompTimeOffset = 0x800
+)
+
+func readFragment(r io.ReaderAt, moof box, defaultSampleDuration uint32) (Fragment, error) {
+	var frag = Fragment{
+		ByteRange: ByteRange{Offset: moof.Offset, Length: moof.Size},
+	}
+
+	traf, ok := find(r, moof, "traf")
+	if !ok {
+		return frag, fmt.Errorf("%w: no traf in moof at %d", ErrInvalidBox, moof.Offset)
+	}
+
+	trafChildren, err := children(r, traf)
+	if err != nil {
+		return frag, err
+	}
+
+	for _, b := range trafChildren {
+		p, err := readPayload(r, b)
+		if err != nil {
+			return frag, err
+		}
+
+		switch b.Type {
+		case "tfhd":
+			if len(p) < 8 {
+				return frag, fmt.Errorf("%w: tfhd is too short", ErrInvalidBox)
+			}
+			var (
+				flags = binary.BigEndian.Uint32(p[0:4]) & 0xFFFFFF
+				pos   = 8
+			)
+			if flags&tfhdBaseDataOffset != 0 {
+				pos += 8
+			}
+			if flags&tfhdSampleDescIndex != 0 {
+				pos += 4
+			}
+			if flags&tfhdDefaultSampleDur != 0 && len(p) >= pos+4 {
+				defaultSampleDuration = binary.BigEndian.Uint32(p[pos : pos+4])
+			}
+		case "tfdt":
+			switch {
+			case len(p) >= 12 && p[0] == 1:
+				frag.DecodeTime = binary.BigEndian.Uint64(p[4:12])
+			case len(p) >= 8:
+				frag.DecodeTime = uint64(binary.BigEndian.Uint32(p[4:8]))
+			}
+		case "trun":
+			dur, err := trunDuration(p, defaultSampleDuration)
+			if err != nil {
+				return frag, err
+			}
+			frag.Duration += dur
+		}
+	}
+
+	return frag, nil
+}
+
+func trunDuration(p []byte, defaultSampleDuration uint32) (uint64, error) {
+	if len(p) < 8 {
+		return 0, fmt.Errorf("%w: trun is too short", ErrInvalidBox)
+	}
+
+	var (
+		flags       = binary.BigEndian.Uint32(p[0:4]) & 0xFFFFFF
+		sampleCount = binary.BigEndian.Uint32(p[4:8])
+		pos         = 8
+		sampleSize  = 0
+		total       uint64
+	)
+
+	if flags&trunDataOffset != 0 {
+		pos += 4
+	}
+	if flags&trunFirstSampleFlags != 0 {
+		pos += 4
+	}
+
+	if flags&trunSampleDuration == 0 {
+		return uint64(sampleCount) * uint64(defaultSampleDuration), nil
+	}
+
+	for _, f := range []uint32{trunSampleDuration, trunSampleSize, trunSampleFlags, trunSampleCompTimeOffset} {
+		if flags&f != 0 {
+			sampleSize += 4
+		}
+	}
+
+	for i := range int(sampleCount) {
+		var at = pos + i*sampleSize
+		if len(p) < at+4 {
+			return 0, fmt.Errorf("%w: trun is too short", ErrInvalidBox)
+		}
+		total += uint64(binary.BigEndian.Uint32(p[at : at+4]))
+	}
+
+	return total, nil
+}
@@ -0,0 +1,43 @@
+package mp4
+
+import (
+	"encoding/hex"
+	"io"
+)
+
+// PSSH is a protection system specific header box of encrypted file
+type PSSH struct {
+	SystemID string // hex, without dashes
+	Box      []byte // whole box, header included
+}
+
+func readPSSH(r io.ReaderAt, moov box) []PSSH {
+	boxes, err := children(r, moov)
+	if err != nil {
+		return nil
+	}
+
+	var res []PSSH
+	for _, b := range boxes {
+		if b.Type != "pssh" {
+			continue
+		}
+
+		var buf = make([]byte, b.Size)
+		if _, err := r.ReadAt(buf, b.Offset); err != nil {
+			continue
+		}
+
+		// header + version and flags (4) + system id (16)
+		var idOffset = b.Header + 4
+		if int64(len(buf)) < idOffset+16 {
+			continue
+		}
+
+		res = append(res, PSSH{
+			SystemID: hex.EncodeToString(buf[idOffset : idOffset+16]),
+			Box:      buf,
+		})
+	}
+	return res
+}
@@ -0,0 +1,112 @@
+// Package mp4encrypt wraps Bento4 mp4encrypt tool
+// for common encryption (ISO/IEC 23001-7) of fragmented mp4 files.
+package mp4encrypt
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var bin = "mp4encrypt"
+
+func SetBin(v string) {
+	bin = v
+}
+
+const (
+	SchemeCENC = "cenc"
+	SchemeCBCS = "cbcs"
+
+	// W3C Common PSSH box format, system id of ClearKey
+	// see https://www.w3.org/TR/eme-initdata-cenc/
+	SystemIDClearKey = "1077efecc0b24d02ace33c1e52e2fb4b"
+
+	// renditions and audio tracks are single-track files
+	trackID = 1
+)
+
+type Params struct {
+	Scheme string
+	KeyID  []byte // 16 bytes
+	Key    []byte // 16 bytes
+	IV     []byte // 8 bytes for cenc, 16 bytes (constant IV) for cbcs
+	PSSH   []string
+}
+
+// Encrypt encrypts fragmented mp4 file: moov and every moof+mdat.
+func Encrypt(ctx context.Context, src, dst string, p Params) error {
+	args, err := p.args()
+	if err != nil {
+		return err
+	}
+
+	return execute(ctx, src, append(args, src, dst))
+}
+
+// EncryptFragments encrypts standalone media segment src,
+// track info is taken from clear (not yet encrypted) init segment.
+func EncryptFragments(ctx context.Context, init, src, dst string, p Params) error {
+	args, err := p.args()
+	if err != nil {
+		return err
+	}
+
+	return execute(ctx, src, append(args, "--fragments-info", init, src, dst))
+}
+
+func (p Params) args() ([]string, error) {
+	var method string
+	switch p.Scheme {
+	case SchemeCENC:
+		method = "MPEG-CENC"
+	case SchemeCBCS:
+		method = "MPEG-CBCS"
+	default:
+		return nil, fmt.Errorf("unknown encryption scheme: %q", p.Scheme)
+	}
+
+	var args = []string{
+		"--method", method,
+		"--key", fmt.Sprintf("%d:%s:%s", trackID, hex.EncodeToString(p.Key), hex.EncodeToString(p.IV)),
+		"--property", fmt.Sprintf("%d:KID:%s", trackID, hex.EncodeToString(p.KeyID)),
+	}
+
+	// pssh v1 lists key ids, no system specific data needed
+	for _, systemID := range p.PSSH {
+		args = append(args, "--pssh-v1", systemID+":"+os.DevNull)
+	}
+
+	return args, nil
+}
+
+// args contain content key, so they are never logged
+func execute(ctx context.Context, src string, args []string) error {
+	log.WithFields(log.Fields{
+		"mod":  "mp4encrypt",
+		"file": src,
+	}).Debug("execute")
+
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout = &bytes.Buffer{}
+	cmd.Stderr = &bytes.Buffer{}
+
+	if err := cmd.Run(); err != nil {
+		var stderr = cmd.Stderr.(*bytes.Buffer).String()
+		log.WithFields(log.Fields{
+			"mod":    "mp4encrypt",
+			"file":   src,
+			"stdout": cmd.Stdout.(*bytes.Buffer).String(),
+			"stderr": stderr,
+		}).Error(err)
+		return fmt.Errorf("mp4encrypt: %w: %s", err, strings.TrimSpace(stderr))
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type FS struct {
+	root string
+}
+
+func NewFS(root string) *FS {
+	return &FS{root: root}
+}
+
+// copies src to root/key, file appears under the final name only when fully written
+func (s *FS) Put(ctx context.Context, key, src string) error {
+	var dst = s.Location(key)
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(out.Name(), dst)
+}
+
+func (s *FS) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	return os.Open(s.Location(key))
+}
+
+func (s *FS) Location(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
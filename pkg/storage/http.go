@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const httpRetries = 3
+
+var httpClient = http.Client{
+	Timeout: 10 * time.Minute,
+	Transport: &http.Transport{
+		DisableCompression: true,
+	},
+}
+
+// HTTP uploads every file with PUT <base url>/<key>
+type HTTP struct {
+	baseURL string
+	headers map[string]string
+}
+
+func NewHTTP(baseURL string, headers map[string]string) *HTTP {
+	return &HTTP{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		headers: headers,
+	}
+}
+
+func (s *HTTP) Put(ctx context.Context, key, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	for n := range httpRetries {
+		if n > 0 {
+			if err := sleep(ctx, time.Duration(n+1)*100*time.Millisecond); err != nil {
+				return err
+			}
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		}
+
+		if err = s.put(ctx, key, f, info.Size()); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (s *HTTP) put(ctx context.Context, key string, body io.Reader, size int64) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPut, s.Location(key), io.NopCloser(body))
+	if err != nil {
+		return err
+	}
+
+	r.ContentLength = size
+	r.Header.Set("Content-Type", ContentType(key))
+	for k, v := range s.headers {
+		r.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("put %v: unexpected status: %v", s.Location(key), resp.Status)
+	}
+
+	return nil
+}
+
+func (s *HTTP) Location(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// sleep waits for d, returns early with ctx error once it is done
+func sleep(ctx context.Context, d time.Duration) error {
+	var timer = time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
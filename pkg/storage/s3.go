@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+// S3 works with any S3-compatible storage (AWS, MinIO, etc.)
+type S3 struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func NewS3Client(cfg S3Config) (*minio.Client, error) {
+	return minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+}
+
+func NewS3(client *minio.Client, bucket, prefix string) *S3 {
+	return &S3{
+		client: client,
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+func (s *S3) Put(ctx context.Context, key, src string) error {
+	_, err := s.client.FPutObject(ctx, s.bucket, join(s.prefix, key), src, minio.PutObjectOptions{
+		ContentType: ContentType(key),
+	})
+	return err
+}
+
+// object is fetched lazily, every Seek + Read is a ranged GET
+func (s *S3) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, join(s.prefix, key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject does not touch the storage, make missing keys fail here
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, fmt.Errorf("%s: %w", s.Location(key), fs.ErrNotExist)
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *S3) Location(key string) string {
+	return "s3://" + s.bucket + "/" + join(s.prefix, key)
+}
@@ -0,0 +1,50 @@
+// Package storage publishes finished task assets to their destination.
+package storage
+
+import (
+	"context"
+	"io"
+	"mime"
+	"path"
+	"strings"
+)
+
+// Storage puts local files under slash-separated keys,
+// keys are relative to the root (directory, url, bucket prefix) of the storage.
+type Storage interface {
+	Put(ctx context.Context, key, src string) error
+	// location of the stored key, for logs and api responses
+	Location(key string) string
+}
+
+// Opener is implemented by storages stored keys can be read back from,
+// missing keys fail with an error matching fs.ErrNotExist
+type Opener interface {
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+}
+
+var contentTypes = map[string]string{
+	".m3u8": "application/vnd.apple.mpegurl",
+	".mpd":  "application/dash+xml",
+	".mp4":  "video/mp4",
+	".m4s":  "video/iso.segment",
+	".vtt":  "text/vtt",
+	".jpg":  "image/jpeg",
+	".webp": "image/webp",
+	".json": "application/json",
+}
+
+func ContentType(key string) string {
+	var ext = strings.ToLower(path.Ext(key))
+	if ct, ok := contentTypes[ext]; ok {
+		return ct
+	}
+	if ct := mime.TypeByExtension(ext); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+func join(prefix, key string) string {
+	return strings.TrimPrefix(path.Join(prefix, key), "/")
+}
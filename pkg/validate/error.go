@@ -36,6 +36,14 @@ func message(e validator.FieldError) string {
 		return fmt.Sprintf("%s must be less than %s", name, e.Param())
 	case "gt":
 		return fmt.Sprintf("%s must be greater than %s", name, e.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", name, e.Param())
+	case "excluded_with":
+		return fmt.Sprintf("%s can not be set together with %s", name, e.Param())
+	case "multiple_of":
+		return fmt.Sprintf("%s must be a multiple of %s", name, e.Param())
+	case "local_path":
+		return fmt.Sprintf("%s must be a relative path not escaping its directory", name)
 	}
 
 	return e.Error()
@@ -1,6 +1,7 @@
 package validate
 
 import (
+	"path/filepath"
 	"reflect"
 	"strings"
 
@@ -17,6 +18,43 @@ func init() {
 		}
 		return name
 	})
+	_ = v.RegisterValidation("multiple_of", multipleOf)
+	_ = v.RegisterValidation("local_path", localPath)
+}
+
+// local_path: relative path staying inside the directory it is joined to,
+// absolute paths and ones escaping it with .. are rejected
+func localPath(fl validator.FieldLevel) bool {
+	return filepath.IsLocal(fl.Field().String())
+}
+
+// multiple_of=Field: the number is a whole multiple of a sibling number,
+// or of every value of a sibling map, zeros are skipped
+func multipleOf(fl validator.FieldLevel) bool {
+	var (
+		n     = fl.Field().Int()
+		other = reflect.Indirect(fl.Parent()).FieldByName(fl.Param())
+	)
+	if n == 0 || !other.IsValid() {
+		return true
+	}
+
+	var divides = func(d reflect.Value) bool {
+		return d.Int() == 0 || n%d.Int() == 0
+	}
+
+	switch other.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return divides(other)
+	case reflect.Map:
+		for _, k := range other.MapKeys() {
+			if !divides(other.MapIndex(k)) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
 }
 
 func Struct(i any) InvalidParamsErr {
@@ -3,6 +3,8 @@ package composer
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"math"
+	"slices"
 
 	"github.com/timohahaa/transcoder/pkg/ffmpeg"
 	"google.golang.org/protobuf/proto"
@@ -22,55 +24,129 @@ func (t *Task) Presets() []ffmpeg.Preset {
 	var presets []ffmpeg.Preset
 	for _, p := range t.Video.Presets {
 		presets = append(presets, ffmpeg.Preset{
-			Quality:        p.Quality,
-			MaxBitRate:     p.MaxBitRate,
-			MinBitRate:     p.MinBitRate,
-			FPS:            p.FPS,
-			Codec:          p.Codec,
-			Bufsize:        p.Bufsize,
-			GOPSeconds:     p.GOPSeconds,
-			Profile:        p.Profile,
-			Level:          p.Level,
-			CRF:            p.CRF,
-			ColorTrc:       p.ColorTrc,
-			ColorSpace:     p.ColorSpace,
-			ColorPrimaries: p.ColorPrimaries,
-			Tune:           p.Tune,
-			Transpose:      p.Transpose,
-			IsVertical:     p.IsVertical,
-			Width:          int(p.Width),
-			Height:         int(p.Height),
+			Quality:          p.Quality,
+			MaxBitRate:       p.MaxBitRate,
+			MinBitRate:       p.MinBitRate,
+			FPS:              p.FPS,
+			Codec:            p.Codec,
+			Bufsize:          p.Bufsize,
+			GOPSeconds:       p.GOPSeconds,
+			Profile:          p.Profile,
+			Level:            p.Level,
+			CRF:              p.CRF,
+			ColorTrc:         p.ColorTrc,
+			ColorSpace:       p.ColorSpace,
+			ColorPrimaries:   p.ColorPrimaries,
+			Tune:             p.Tune,
+			Transpose:        p.Transpose,
+			IsVertical:       p.IsVertical,
+			Width:            int(p.Width),
+			Height:           int(p.Height),
+			ChunkStart:       t.Video.Start,
+			Tonemap:          p.Tonemap,
+			SourceColorSpace: p.SourceColorSpace,
+			SourceColorRange: p.SourceColorRange,
+			HDR:              p.HDR,
+			Mastering:        p.Mastering.Metadata(),
+			Deinterlace:      p.Deinterlace.Options(),
 		})
 	}
 	return presets
 }
 
+// presets to encode, only renditions failing the quality gate on retries
+func (t *Task) EncodePresets() []ffmpeg.Preset {
+	var (
+		presets    = t.Presets()
+		renditions = t.GetVideo().GetGate().GetRenditions()
+	)
+	if len(renditions) == 0 {
+		return presets
+	}
+
+	return slices.DeleteFunc(presets, func(p ffmpeg.Preset) bool {
+		return !slices.Contains(renditions, p.Name())
+	})
+}
+
 func (q *Preset) Marshal() ([]byte, error) { return proto.Marshal(q) }
 func (q *Preset) Unmarshal(b []byte) error { return proto.Unmarshal(b, q) }
 
+// rendition name, see ffmpeg.Preset.Name
+func (q *Preset) Name() string {
+	return ffmpeg.Preset{Quality: q.Quality, Codec: q.Codec, HDR: q.HDR}.Name()
+}
+
 func (q *Preset) Copy() *Preset {
 	if q == nil {
 		return nil
 	}
 	return &Preset{
-		Quality:        q.Quality,
-		MaxBitRate:     q.MaxBitRate,
-		MinBitRate:     q.MinBitRate,
-		FPS:            q.FPS,
-		Codec:          q.Codec,
-		Bufsize:        q.Bufsize,
-		GOPSeconds:     q.GOPSeconds,
-		Profile:        q.Profile,
-		Level:          q.Level,
-		CRF:            q.CRF,
-		ColorTrc:       q.ColorTrc,
-		ColorSpace:     q.ColorSpace,
-		ColorPrimaries: q.ColorPrimaries,
-		Tune:           q.Tune,
-		Transpose:      q.Transpose,
-		IsVertical:     q.IsVertical,
-		Width:          q.Width,
-		Height:         q.Height,
+		Quality:          q.Quality,
+		MaxBitRate:       q.MaxBitRate,
+		MinBitRate:       q.MinBitRate,
+		FPS:              q.FPS,
+		Codec:            q.Codec,
+		Bufsize:          q.Bufsize,
+		GOPSeconds:       q.GOPSeconds,
+		Profile:          q.Profile,
+		Level:            q.Level,
+		CRF:              q.CRF,
+		ColorTrc:         q.ColorTrc,
+		ColorSpace:       q.ColorSpace,
+		ColorPrimaries:   q.ColorPrimaries,
+		Tune:             q.Tune,
+		Transpose:        q.Transpose,
+		IsVertical:       q.IsVertical,
+		Width:            q.Width,
+		Height:           q.Height,
+		Tonemap:          q.Tonemap,
+		SourceColorSpace: q.SourceColorSpace,
+		SourceColorRange: q.SourceColorRange,
+		HDR:              q.HDR,
+		Mastering:        q.Mastering.Copy(),
+		Deinterlace:      q.Deinterlace.Copy(),
+	}
+}
+
+func (m *HDRMetadata) Copy() *HDRMetadata {
+	if m == nil {
+		return nil
+	}
+	return proto.Clone(m).(*HDRMetadata)
+}
+
+func (m *HDRMetadata) Metadata() *ffmpeg.HDRMetadata {
+	if m == nil {
+		return nil
+	}
+	return &ffmpeg.HDRMetadata{
+		Red:          [2]float64{float64(m.RedX), float64(m.RedY)},
+		Green:        [2]float64{float64(m.GreenX), float64(m.GreenY)},
+		Blue:         [2]float64{float64(m.BlueX), float64(m.BlueY)},
+		WhitePoint:   [2]float64{float64(m.WhiteX), float64(m.WhiteY)},
+		MaxLuminance: float64(m.MaxLuminance),
+		MinLuminance: float64(m.MinLuminance),
+		MaxCLL:       int(m.MaxCLL),
+		MaxFALL:      int(m.MaxFALL),
+	}
+}
+
+func (d *DeinterlacePreset) Copy() *DeinterlacePreset {
+	if d == nil {
+		return nil
+	}
+	return proto.Clone(d).(*DeinterlacePreset)
+}
+
+func (d *DeinterlacePreset) Options() *ffmpeg.Deinterlace {
+	if d == nil {
+		return nil
+	}
+	return &ffmpeg.Deinterlace{
+		Filter:     d.Filter,
+		FieldOrder: d.FieldOrder,
+		FieldRate:  d.FieldRate,
 	}
 }
 
@@ -112,6 +188,59 @@ func (ap *AudioPreset) Copy() *AudioPreset {
 		PadAfter:     ap.PadAfter,
 		TrimBefore:   ap.TrimBefore,
 		TrimDuration: ap.TrimDuration,
+		Loudness:     ap.Loudness.Copy(),
+		Renditions:   copyRenditions(ap.Renditions),
+
+		ChannelLayout: ap.ChannelLayout,
+		Downmix:       ap.Downmix.Copy(),
+	}
+}
+
+func (dp *DownmixPreset) Copy() *DownmixPreset {
+	if dp == nil {
+		return nil
+	}
+	return &DownmixPreset{
+		Center:   dp.Center,
+		Surround: dp.Surround,
+		LFE:      dp.LFE,
+		Encoding: dp.Encoding,
+	}
+}
+
+func copyRenditions(rs []*AudioRendition) []*AudioRendition {
+	if rs == nil {
+		return nil
+	}
+	var res = make([]*AudioRendition, 0, len(rs))
+	for _, r := range rs {
+		res = append(res, &AudioRendition{
+			Name:       r.Name,
+			Codec:      r.Codec,
+			Bitrate:    r.Bitrate,
+			Channels:   r.Channels,
+			SampleRate: r.SampleRate,
+
+			ChannelLayout: r.ChannelLayout,
+			Loudness:      r.Loudness.Copy(),
+		})
+	}
+	return res
+}
+
+func (lp *LoudnessPreset) Copy() *LoudnessPreset {
+	if lp == nil {
+		return nil
+	}
+	return &LoudnessPreset{
+		TargetI:        lp.TargetI,
+		TargetTP:       lp.TargetTP,
+		TargetLRA:      lp.TargetLRA,
+		MeasuredI:      lp.MeasuredI,
+		MeasuredTP:     lp.MeasuredTP,
+		MeasuredLRA:    lp.MeasuredLRA,
+		MeasuredThresh: lp.MeasuredThresh,
+		Offset:         lp.Offset,
 	}
 }
 
@@ -127,5 +256,137 @@ func (a *AudioPreset) Preset() ffmpeg.AudioPreset {
 		PadAfter:     float64(a.PadAfter),
 		TrimBefore:   float64(a.TrimBefore),
 		TrimDuration: float64(a.TrimDuration),
+		Loudnorm:     a.Loudness.Loudnorm(),
+		Renditions:   a.renditions(),
+
+		ChannelLayout: a.ChannelLayout,
+		Downmix:       a.Downmix.Downmix(),
+	}
+}
+
+func (d *DownmixPreset) Downmix() *ffmpeg.Downmix {
+	if d == nil {
+		return nil
+	}
+	return &ffmpeg.Downmix{
+		Center:   float64(d.Center),
+		Surround: float64(d.Surround),
+		LFE:      float64(d.LFE),
+		Encoding: d.Encoding,
+	}
+}
+
+func (a *AudioPreset) renditions() []ffmpeg.AudioRendition {
+	if len(a.Renditions) == 0 {
+		return nil
+	}
+	var res = make([]ffmpeg.AudioRendition, 0, len(a.Renditions))
+	for _, r := range a.Renditions {
+		res = append(res, ffmpeg.AudioRendition{
+			Name:       r.Name,
+			Codec:      r.Codec,
+			Bitrate:    r.Bitrate,
+			Channels:   int(r.Channels),
+			SampleRate: r.SampleRate,
+
+			ChannelLayout: r.ChannelLayout,
+			Loudnorm:      r.Loudness.Loudnorm(),
+		})
+	}
+	return res
+}
+
+func (l *LoudnessPreset) Loudnorm() *ffmpeg.Loudnorm {
+	if l == nil {
+		return nil
+	}
+	return &ffmpeg.Loudnorm{
+		Target: ffmpeg.LoudnessTarget{
+			I:   float64(l.TargetI),
+			TP:  float64(l.TargetTP),
+			LRA: float64(l.TargetLRA),
+		},
+		Measured: ffmpeg.Loudness{
+			I:      float64(l.MeasuredI),
+			TP:     float64(l.MeasuredTP),
+			LRA:    float64(l.MeasuredLRA),
+			Thresh: float64(l.MeasuredThresh),
+			Offset: float64(l.Offset),
+		},
+	}
+}
+
+// NewMetricScores summarizes per-frame scores, nil if there are none
+func NewMetricScores(scores []float64, step float64) *MetricScores {
+	if len(scores) == 0 {
+		return nil
+	}
+
+	var (
+		res = &MetricScores{
+			Min:       math.Inf(1),
+			Frames:    int64(len(scores)),
+			Step:      step,
+			Histogram: make(map[int64]int64),
+		}
+		sum float64
+	)
+	for _, s := range scores {
+		sum += s
+		res.Min = min(res.Min, s)
+		res.Histogram[int64(math.Round(s/step))]++
+	}
+	res.Mean = sum / float64(len(scores))
+
+	return res
+}
+
+// Merge adds scores of another chunk, histograms must have the same step
+func (m *MetricScores) Merge(o *MetricScores) *MetricScores {
+	switch {
+	case m == nil:
+		return o
+	case o == nil:
+		return m
+	}
+
+	var res = &MetricScores{
+		Mean:      (m.Mean*float64(m.Frames) + o.Mean*float64(o.Frames)) / float64(m.Frames+o.Frames),
+		Min:       min(m.Min, o.Min),
+		Frames:    m.Frames + o.Frames,
+		Step:      m.Step,
+		Histogram: make(map[int64]int64, len(m.Histogram)),
+	}
+	for bin, n := range m.Histogram {
+		res.Histogram[bin] += n
+	}
+	for bin, n := range o.Histogram {
+		res.Histogram[bin] += n
+	}
+	return res
+}
+
+// Percentile of frame scores, p is in [0, 100], precise to Step
+func (m *MetricScores) Percentile(p float64) float64 {
+	if m == nil || m.Frames == 0 {
+		return 0
+	}
+
+	var bins = make([]int64, 0, len(m.Histogram))
+	for bin := range m.Histogram {
+		bins = append(bins, bin)
+	}
+	slices.Sort(bins)
+
+	var (
+		rank  = int64(math.Ceil(p / 100 * float64(m.Frames)))
+		count int64
+	)
+	for _, bin := range bins {
+		count += m.Histogram[bin]
+		if count >= rank {
+			return float64(bin) * m.Step
+		}
 	}
+	return float64(bins[len(bins)-1]) * m.Step
 }
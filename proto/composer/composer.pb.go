@@ -89,6 +89,7 @@ type FinishTaskRequest struct {
 	Task          *Task                  `protobuf:"bytes,1,opt,name=Task,proto3" json:"Task,omitempty"`
 	Error         *Error                 `protobuf:"bytes,2,opt,name=Error,proto3" json:"Error,omitempty"`
 	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=FinishedAt,proto3" json:"FinishedAt,omitempty"`
+	Metrics       []*RenditionMetrics    `protobuf:"bytes,4,rep,name=Metrics,proto3" json:"Metrics,omitempty"` // quality metrics mode only
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -144,6 +145,159 @@ func (x *FinishTaskRequest) GetFinishedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *FinishTaskRequest) GetMetrics() []*RenditionMetrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+// scores of a rendition chunk against the source chunk
+type RenditionMetrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rendition     string                 `protobuf:"bytes,1,opt,name=Rendition,proto3" json:"Rendition,omitempty"`
+	VMAF          *MetricScores          `protobuf:"bytes,2,opt,name=VMAF,proto3" json:"VMAF,omitempty"` // nil if libvmaf is missing
+	PSNR          *MetricScores          `protobuf:"bytes,3,opt,name=PSNR,proto3" json:"PSNR,omitempty"` // luma, dB
+	SSIM          *MetricScores          `protobuf:"bytes,4,opt,name=SSIM,proto3" json:"SSIM,omitempty"` // luma
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenditionMetrics) Reset() {
+	*x = RenditionMetrics{}
+	mi := &file_proto_composer_composer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenditionMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenditionMetrics) ProtoMessage() {}
+
+func (x *RenditionMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_composer_composer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenditionMetrics.ProtoReflect.Descriptor instead.
+func (*RenditionMetrics) Descriptor() ([]byte, []int) {
+	return file_proto_composer_composer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RenditionMetrics) GetRendition() string {
+	if x != nil {
+		return x.Rendition
+	}
+	return ""
+}
+
+func (x *RenditionMetrics) GetVMAF() *MetricScores {
+	if x != nil {
+		return x.VMAF
+	}
+	return nil
+}
+
+func (x *RenditionMetrics) GetPSNR() *MetricScores {
+	if x != nil {
+		return x.PSNR
+	}
+	return nil
+}
+
+func (x *RenditionMetrics) GetSSIM() *MetricScores {
+	if x != nil {
+		return x.SSIM
+	}
+	return nil
+}
+
+// per-frame scores, histogram allows chunks to be merged exactly
+type MetricScores struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mean          float64                `protobuf:"fixed64,1,opt,name=Mean,proto3" json:"Mean,omitempty"`
+	Min           float64                `protobuf:"fixed64,2,opt,name=Min,proto3" json:"Min,omitempty"`
+	Frames        int64                  `protobuf:"varint,3,opt,name=Frames,proto3" json:"Frames,omitempty"`
+	Step          float64                `protobuf:"fixed64,4,opt,name=Step,proto3" json:"Step,omitempty"`                                                                                     // histogram bin width
+	Histogram     map[int64]int64        `protobuf:"bytes,5,rep,name=Histogram,proto3" json:"Histogram,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // round(score / Step) -> frames
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricScores) Reset() {
+	*x = MetricScores{}
+	mi := &file_proto_composer_composer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricScores) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricScores) ProtoMessage() {}
+
+func (x *MetricScores) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_composer_composer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricScores.ProtoReflect.Descriptor instead.
+func (*MetricScores) Descriptor() ([]byte, []int) {
+	return file_proto_composer_composer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MetricScores) GetMean() float64 {
+	if x != nil {
+		return x.Mean
+	}
+	return 0
+}
+
+func (x *MetricScores) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *MetricScores) GetFrames() int64 {
+	if x != nil {
+		return x.Frames
+	}
+	return 0
+}
+
+func (x *MetricScores) GetStep() float64 {
+	if x != nil {
+		return x.Step
+	}
+	return 0
+}
+
+func (x *MetricScores) GetHistogram() map[int64]int64 {
+	if x != nil {
+		return x.Histogram
+	}
+	return nil
+}
+
 type UpdateProgressRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ID            []byte                 `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`       // taskID
@@ -154,7 +308,7 @@ type UpdateProgressRequest struct {
 
 func (x *UpdateProgressRequest) Reset() {
 	*x = UpdateProgressRequest{}
-	mi := &file_proto_composer_composer_proto_msgTypes[2]
+	mi := &file_proto_composer_composer_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -166,7 +320,7 @@ func (x *UpdateProgressRequest) String() string {
 func (*UpdateProgressRequest) ProtoMessage() {}
 
 func (x *UpdateProgressRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_composer_composer_proto_msgTypes[2]
+	mi := &file_proto_composer_composer_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -179,7 +333,7 @@ func (x *UpdateProgressRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateProgressRequest.ProtoReflect.Descriptor instead.
 func (*UpdateProgressRequest) Descriptor() ([]byte, []int) {
-	return file_proto_composer_composer_proto_rawDescGZIP(), []int{2}
+	return file_proto_composer_composer_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *UpdateProgressRequest) GetID() []byte {
@@ -208,7 +362,7 @@ type Error struct {
 
 func (x *Error) Reset() {
 	*x = Error{}
-	mi := &file_proto_composer_composer_proto_msgTypes[3]
+	mi := &file_proto_composer_composer_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -220,7 +374,7 @@ func (x *Error) String() string {
 func (*Error) ProtoMessage() {}
 
 func (x *Error) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_composer_composer_proto_msgTypes[3]
+	mi := &file_proto_composer_composer_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -233,7 +387,7 @@ func (x *Error) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Error.ProtoReflect.Descriptor instead.
 func (*Error) Descriptor() ([]byte, []int) {
-	return file_proto_composer_composer_proto_rawDescGZIP(), []int{3}
+	return file_proto_composer_composer_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Error) GetReason() string {
@@ -265,13 +419,28 @@ const file_proto_composer_composer_proto_rawDesc = "" +
 	"\x0eGetTaskRequest\x12\x18\n" +
 	"\aEncoder\x18\x01 \x01(\tR\aEncoder\x12\x1a\n" +
 	"\bHostname\x18\x02 \x01(\tR\bHostname\x12$\n" +
-	"\rFFmpegVersion\x18\x03 \x01(\tR\rFFmpegVersion\"\x9a\x01\n" +
+	"\rFFmpegVersion\x18\x03 \x01(\tR\rFFmpegVersion\"\xd0\x01\n" +
 	"\x11FinishTaskRequest\x12\"\n" +
 	"\x04Task\x18\x01 \x01(\v2\x0e.composer.TaskR\x04Task\x12%\n" +
 	"\x05Error\x18\x02 \x01(\v2\x0f.composer.ErrorR\x05Error\x12:\n" +
 	"\n" +
 	"FinishedAt\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
-	"FinishedAt\"X\n" +
+	"FinishedAt\x124\n" +
+	"\aMetrics\x18\x04 \x03(\v2\x1a.composer.RenditionMetricsR\aMetrics\"\xb4\x01\n" +
+	"\x10RenditionMetrics\x12\x1c\n" +
+	"\tRendition\x18\x01 \x01(\tR\tRendition\x12*\n" +
+	"\x04VMAF\x18\x02 \x01(\v2\x16.composer.MetricScoresR\x04VMAF\x12*\n" +
+	"\x04PSNR\x18\x03 \x01(\v2\x16.composer.MetricScoresR\x04PSNR\x12*\n" +
+	"\x04SSIM\x18\x04 \x01(\v2\x16.composer.MetricScoresR\x04SSIM\"\xe3\x01\n" +
+	"\fMetricScores\x12\x12\n" +
+	"\x04Mean\x18\x01 \x01(\x01R\x04Mean\x12\x10\n" +
+	"\x03Min\x18\x02 \x01(\x01R\x03Min\x12\x16\n" +
+	"\x06Frames\x18\x03 \x01(\x03R\x06Frames\x12\x12\n" +
+	"\x04Step\x18\x04 \x01(\x01R\x04Step\x12C\n" +
+	"\tHistogram\x18\x05 \x03(\v2%.composer.MetricScores.HistogramEntryR\tHistogram\x1a<\n" +
+	"\x0eHistogramEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x03R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"X\n" +
 	"\x15UpdateProgressRequest\x12\x0e\n" +
 	"\x02ID\x18\x01 \x01(\fR\x02ID\x12/\n" +
 	"\x05Delta\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x05Delta\"\xaf\x01\n" +
@@ -300,35 +469,43 @@ func file_proto_composer_composer_proto_rawDescGZIP() []byte {
 	return file_proto_composer_composer_proto_rawDescData
 }
 
-var file_proto_composer_composer_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_proto_composer_composer_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_proto_composer_composer_proto_goTypes = []any{
 	(*GetTaskRequest)(nil),        // 0: composer.GetTaskRequest
 	(*FinishTaskRequest)(nil),     // 1: composer.FinishTaskRequest
-	(*UpdateProgressRequest)(nil), // 2: composer.UpdateProgressRequest
-	(*Error)(nil),                 // 3: composer.Error
-	nil,                           // 4: composer.Error.MetadataEntry
-	(*Task)(nil),                  // 5: composer.Task
-	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
-	(*durationpb.Duration)(nil),   // 7: google.protobuf.Duration
-	(*emptypb.Empty)(nil),         // 8: google.protobuf.Empty
+	(*RenditionMetrics)(nil),      // 2: composer.RenditionMetrics
+	(*MetricScores)(nil),          // 3: composer.MetricScores
+	(*UpdateProgressRequest)(nil), // 4: composer.UpdateProgressRequest
+	(*Error)(nil),                 // 5: composer.Error
+	nil,                           // 6: composer.MetricScores.HistogramEntry
+	nil,                           // 7: composer.Error.MetadataEntry
+	(*Task)(nil),                  // 8: composer.Task
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 10: google.protobuf.Duration
+	(*emptypb.Empty)(nil),         // 11: google.protobuf.Empty
 }
 var file_proto_composer_composer_proto_depIdxs = []int32{
-	5, // 0: composer.FinishTaskRequest.Task:type_name -> composer.Task
-	3, // 1: composer.FinishTaskRequest.Error:type_name -> composer.Error
-	6, // 2: composer.FinishTaskRequest.FinishedAt:type_name -> google.protobuf.Timestamp
-	7, // 3: composer.UpdateProgressRequest.Delta:type_name -> google.protobuf.Duration
-	4, // 4: composer.Error.metadata:type_name -> composer.Error.MetadataEntry
-	0, // 5: composer.Composer.GetTask:input_type -> composer.GetTaskRequest
-	1, // 6: composer.Composer.FinishTask:input_type -> composer.FinishTaskRequest
-	2, // 7: composer.Composer.UpdateProgress:input_type -> composer.UpdateProgressRequest
-	5, // 8: composer.Composer.GetTask:output_type -> composer.Task
-	8, // 9: composer.Composer.FinishTask:output_type -> google.protobuf.Empty
-	8, // 10: composer.Composer.UpdateProgress:output_type -> google.protobuf.Empty
-	8, // [8:11] is the sub-list for method output_type
-	5, // [5:8] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	8,  // 0: composer.FinishTaskRequest.Task:type_name -> composer.Task
+	5,  // 1: composer.FinishTaskRequest.Error:type_name -> composer.Error
+	9,  // 2: composer.FinishTaskRequest.FinishedAt:type_name -> google.protobuf.Timestamp
+	2,  // 3: composer.FinishTaskRequest.Metrics:type_name -> composer.RenditionMetrics
+	3,  // 4: composer.RenditionMetrics.VMAF:type_name -> composer.MetricScores
+	3,  // 5: composer.RenditionMetrics.PSNR:type_name -> composer.MetricScores
+	3,  // 6: composer.RenditionMetrics.SSIM:type_name -> composer.MetricScores
+	6,  // 7: composer.MetricScores.Histogram:type_name -> composer.MetricScores.HistogramEntry
+	10, // 8: composer.UpdateProgressRequest.Delta:type_name -> google.protobuf.Duration
+	7,  // 9: composer.Error.metadata:type_name -> composer.Error.MetadataEntry
+	0,  // 10: composer.Composer.GetTask:input_type -> composer.GetTaskRequest
+	1,  // 11: composer.Composer.FinishTask:input_type -> composer.FinishTaskRequest
+	4,  // 12: composer.Composer.UpdateProgress:input_type -> composer.UpdateProgressRequest
+	8,  // 13: composer.Composer.GetTask:output_type -> composer.Task
+	11, // 14: composer.Composer.FinishTask:output_type -> google.protobuf.Empty
+	11, // 15: composer.Composer.UpdateProgress:output_type -> google.protobuf.Empty
+	13, // [13:16] is the sub-list for method output_type
+	10, // [10:13] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_proto_composer_composer_proto_init() }
@@ -343,7 +520,7 @@ func file_proto_composer_composer_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_composer_composer_proto_rawDesc), len(file_proto_composer_composer_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -22,27 +22,33 @@ const (
 )
 
 type Preset struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Quality        string                 `protobuf:"bytes,1,opt,name=Quality,proto3" json:"Quality,omitempty"` // 360, 720, 1440, etc...
-	MaxBitRate     int64                  `protobuf:"varint,2,opt,name=MaxBitRate,proto3" json:"MaxBitRate,omitempty"`
-	MinBitRate     int64                  `protobuf:"varint,3,opt,name=MinBitRate,proto3" json:"MinBitRate,omitempty"`
-	FPS            string                 `protobuf:"bytes,4,opt,name=FPS,proto3" json:"FPS,omitempty"`
-	Codec          string                 `protobuf:"bytes,5,opt,name=Codec,proto3" json:"Codec,omitempty"`
-	Bufsize        int64                  `protobuf:"varint,6,opt,name=Bufsize,proto3" json:"Bufsize,omitempty"`
-	GOPSeconds     int32                  `protobuf:"varint,7,opt,name=GOPSeconds,proto3" json:"GOPSeconds,omitempty"`
-	Profile        string                 `protobuf:"bytes,8,opt,name=Profile,proto3" json:"Profile,omitempty"`
-	Level          string                 `protobuf:"bytes,9,opt,name=Level,proto3" json:"Level,omitempty"`
-	CRF            int32                  `protobuf:"varint,10,opt,name=CRF,proto3" json:"CRF,omitempty"` // -cq for GPU and -crf for CPU
-	ColorTrc       string                 `protobuf:"bytes,11,opt,name=ColorTrc,proto3" json:"ColorTrc,omitempty"`
-	ColorSpace     string                 `protobuf:"bytes,12,opt,name=ColorSpace,proto3" json:"ColorSpace,omitempty"`
-	ColorPrimaries string                 `protobuf:"bytes,13,opt,name=ColorPrimaries,proto3" json:"ColorPrimaries,omitempty"`
-	Tune           string                 `protobuf:"bytes,14,opt,name=Tune,proto3" json:"Tune,omitempty"`           // psnr/ssim/grain/zerolatency/animation/film - content type
-	Transpose      string                 `protobuf:"bytes,15,opt,name=Transpose,proto3" json:"Transpose,omitempty"` // clock/cclock/flip
-	IsVertical     bool                   `protobuf:"varint,16,opt,name=IsVertical,proto3" json:"IsVertical,omitempty"`
-	Width          int32                  `protobuf:"varint,17,opt,name=Width,proto3" json:"Width,omitempty"`
-	Height         int32                  `protobuf:"varint,18,opt,name=Height,proto3" json:"Height,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Quality          string                 `protobuf:"bytes,1,opt,name=Quality,proto3" json:"Quality,omitempty"` // 360, 720, 1440, etc...
+	MaxBitRate       int64                  `protobuf:"varint,2,opt,name=MaxBitRate,proto3" json:"MaxBitRate,omitempty"`
+	MinBitRate       int64                  `protobuf:"varint,3,opt,name=MinBitRate,proto3" json:"MinBitRate,omitempty"`
+	FPS              string                 `protobuf:"bytes,4,opt,name=FPS,proto3" json:"FPS,omitempty"`
+	Codec            string                 `protobuf:"bytes,5,opt,name=Codec,proto3" json:"Codec,omitempty"`
+	Bufsize          int64                  `protobuf:"varint,6,opt,name=Bufsize,proto3" json:"Bufsize,omitempty"`
+	GOPSeconds       int32                  `protobuf:"varint,7,opt,name=GOPSeconds,proto3" json:"GOPSeconds,omitempty"`
+	Profile          string                 `protobuf:"bytes,8,opt,name=Profile,proto3" json:"Profile,omitempty"`
+	Level            string                 `protobuf:"bytes,9,opt,name=Level,proto3" json:"Level,omitempty"`
+	CRF              int32                  `protobuf:"varint,10,opt,name=CRF,proto3" json:"CRF,omitempty"` // -cq for GPU and -crf for CPU
+	ColorTrc         string                 `protobuf:"bytes,11,opt,name=ColorTrc,proto3" json:"ColorTrc,omitempty"`
+	ColorSpace       string                 `protobuf:"bytes,12,opt,name=ColorSpace,proto3" json:"ColorSpace,omitempty"`
+	ColorPrimaries   string                 `protobuf:"bytes,13,opt,name=ColorPrimaries,proto3" json:"ColorPrimaries,omitempty"`
+	Tune             string                 `protobuf:"bytes,14,opt,name=Tune,proto3" json:"Tune,omitempty"`           // psnr/ssim/grain/zerolatency/animation/film - content type
+	Transpose        string                 `protobuf:"bytes,15,opt,name=Transpose,proto3" json:"Transpose,omitempty"` // clock/cclock/flip
+	IsVertical       bool                   `protobuf:"varint,16,opt,name=IsVertical,proto3" json:"IsVertical,omitempty"`
+	Width            int32                  `protobuf:"varint,17,opt,name=Width,proto3" json:"Width,omitempty"`
+	Height           int32                  `protobuf:"varint,18,opt,name=Height,proto3" json:"Height,omitempty"`
+	Tonemap          string                 `protobuf:"bytes,19,opt,name=Tonemap,proto3" json:"Tonemap,omitempty"`                   // transfer of HDR source tone mapped to SDR BT.709, empty if none
+	HDR              bool                   `protobuf:"varint,20,opt,name=HDR,proto3" json:"HDR,omitempty"`                          // 10-bit rendition keeping source transfer and BT.2020
+	Mastering        *HDRMetadata           `protobuf:"bytes,21,opt,name=Mastering,proto3" json:"Mastering,omitempty"`               // HDR renditions only, nil if the source has none
+	SourceColorSpace string                 `protobuf:"bytes,22,opt,name=SourceColorSpace,proto3" json:"SourceColorSpace,omitempty"` // matrix of SDR source, converted into ColorSpace if they differ
+	SourceColorRange string                 `protobuf:"bytes,23,opt,name=SourceColorRange,proto3" json:"SourceColorRange,omitempty"` // tv or pc, full range sources are converted to limited
+	Deinterlace      *DeinterlacePreset     `protobuf:"bytes,24,opt,name=Deinterlace,proto3" json:"Deinterlace,omitempty"`           // nil for progressive sources, FPS is of the deinterlaced video
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *Preset) Reset() {
@@ -201,22 +207,264 @@ func (x *Preset) GetHeight() int32 {
 	return 0
 }
 
-type AudioPreset struct {
+func (x *Preset) GetTonemap() string {
+	if x != nil {
+		return x.Tonemap
+	}
+	return ""
+}
+
+func (x *Preset) GetHDR() bool {
+	if x != nil {
+		return x.HDR
+	}
+	return false
+}
+
+func (x *Preset) GetMastering() *HDRMetadata {
+	if x != nil {
+		return x.Mastering
+	}
+	return nil
+}
+
+func (x *Preset) GetSourceColorSpace() string {
+	if x != nil {
+		return x.SourceColorSpace
+	}
+	return ""
+}
+
+func (x *Preset) GetSourceColorRange() string {
+	if x != nil {
+		return x.SourceColorRange
+	}
+	return ""
+}
+
+func (x *Preset) GetDeinterlace() *DeinterlacePreset {
+	if x != nil {
+		return x.Deinterlace
+	}
+	return nil
+}
+
+// interlaced source, see Settings.Deinterlace
+type DeinterlacePreset struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Channels      int32                  `protobuf:"varint,1,opt,name=Channels,proto3" json:"Channels,omitempty"`
-	Bitrate       int64                  `protobuf:"varint,2,opt,name=Bitrate,proto3" json:"Bitrate,omitempty"`
-	SampleRate    int64                  `protobuf:"varint,3,opt,name=SampleRate,proto3" json:"SampleRate,omitempty"`
-	PadBefore     float32                `protobuf:"fixed32,4,opt,name=PadBefore,proto3" json:"PadBefore,omitempty"`
-	PadAfter      float32                `protobuf:"fixed32,5,opt,name=PadAfter,proto3" json:"PadAfter,omitempty"`
-	TrimBefore    float32                `protobuf:"fixed32,6,opt,name=TrimBefore,proto3" json:"TrimBefore,omitempty"`
-	TrimDuration  float32                `protobuf:"fixed32,7,opt,name=TrimDuration,proto3" json:"TrimDuration,omitempty"`
+	Filter        string                 `protobuf:"bytes,1,opt,name=Filter,proto3" json:"Filter,omitempty"`         // bwdif or yadif
+	FieldOrder    string                 `protobuf:"bytes,2,opt,name=FieldOrder,proto3" json:"FieldOrder,omitempty"` // tff or bff
+	FieldRate     bool                   `protobuf:"varint,3,opt,name=FieldRate,proto3" json:"FieldRate,omitempty"`  // a frame per field, frame rate is doubled
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeinterlacePreset) Reset() {
+	*x = DeinterlacePreset{}
+	mi := &file_proto_composer_preset_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeinterlacePreset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeinterlacePreset) ProtoMessage() {}
+
+func (x *DeinterlacePreset) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_composer_preset_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeinterlacePreset.ProtoReflect.Descriptor instead.
+func (*DeinterlacePreset) Descriptor() ([]byte, []int) {
+	return file_proto_composer_preset_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DeinterlacePreset) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *DeinterlacePreset) GetFieldOrder() string {
+	if x != nil {
+		return x.FieldOrder
+	}
+	return ""
+}
+
+func (x *DeinterlacePreset) GetFieldRate() bool {
+	if x != nil {
+		return x.FieldRate
+	}
+	return false
+}
+
+// static HDR metadata of the source
+type HDRMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RedX          float32                `protobuf:"fixed32,1,opt,name=RedX,proto3" json:"RedX,omitempty"` // chromaticity coordinates of mastering display
+	RedY          float32                `protobuf:"fixed32,2,opt,name=RedY,proto3" json:"RedY,omitempty"`
+	GreenX        float32                `protobuf:"fixed32,3,opt,name=GreenX,proto3" json:"GreenX,omitempty"`
+	GreenY        float32                `protobuf:"fixed32,4,opt,name=GreenY,proto3" json:"GreenY,omitempty"`
+	BlueX         float32                `protobuf:"fixed32,5,opt,name=BlueX,proto3" json:"BlueX,omitempty"`
+	BlueY         float32                `protobuf:"fixed32,6,opt,name=BlueY,proto3" json:"BlueY,omitempty"`
+	WhiteX        float32                `protobuf:"fixed32,7,opt,name=WhiteX,proto3" json:"WhiteX,omitempty"`
+	WhiteY        float32                `protobuf:"fixed32,8,opt,name=WhiteY,proto3" json:"WhiteY,omitempty"`
+	MaxLuminance  float32                `protobuf:"fixed32,9,opt,name=MaxLuminance,proto3" json:"MaxLuminance,omitempty"`  // cd/m2
+	MinLuminance  float32                `protobuf:"fixed32,10,opt,name=MinLuminance,proto3" json:"MinLuminance,omitempty"` // cd/m2
+	MaxCLL        int32                  `protobuf:"varint,11,opt,name=MaxCLL,proto3" json:"MaxCLL,omitempty"`              // content light level, 0 if unknown
+	MaxFALL       int32                  `protobuf:"varint,12,opt,name=MaxFALL,proto3" json:"MaxFALL,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HDRMetadata) Reset() {
+	*x = HDRMetadata{}
+	mi := &file_proto_composer_preset_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HDRMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HDRMetadata) ProtoMessage() {}
+
+func (x *HDRMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_composer_preset_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HDRMetadata.ProtoReflect.Descriptor instead.
+func (*HDRMetadata) Descriptor() ([]byte, []int) {
+	return file_proto_composer_preset_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HDRMetadata) GetRedX() float32 {
+	if x != nil {
+		return x.RedX
+	}
+	return 0
+}
+
+func (x *HDRMetadata) GetRedY() float32 {
+	if x != nil {
+		return x.RedY
+	}
+	return 0
+}
+
+func (x *HDRMetadata) GetGreenX() float32 {
+	if x != nil {
+		return x.GreenX
+	}
+	return 0
+}
+
+func (x *HDRMetadata) GetGreenY() float32 {
+	if x != nil {
+		return x.GreenY
+	}
+	return 0
+}
+
+func (x *HDRMetadata) GetBlueX() float32 {
+	if x != nil {
+		return x.BlueX
+	}
+	return 0
+}
+
+func (x *HDRMetadata) GetBlueY() float32 {
+	if x != nil {
+		return x.BlueY
+	}
+	return 0
+}
+
+func (x *HDRMetadata) GetWhiteX() float32 {
+	if x != nil {
+		return x.WhiteX
+	}
+	return 0
+}
+
+func (x *HDRMetadata) GetWhiteY() float32 {
+	if x != nil {
+		return x.WhiteY
+	}
+	return 0
+}
+
+func (x *HDRMetadata) GetMaxLuminance() float32 {
+	if x != nil {
+		return x.MaxLuminance
+	}
+	return 0
+}
+
+func (x *HDRMetadata) GetMinLuminance() float32 {
+	if x != nil {
+		return x.MinLuminance
+	}
+	return 0
+}
+
+func (x *HDRMetadata) GetMaxCLL() int32 {
+	if x != nil {
+		return x.MaxCLL
+	}
+	return 0
+}
+
+func (x *HDRMetadata) GetMaxFALL() int32 {
+	if x != nil {
+		return x.MaxFALL
+	}
+	return 0
+}
+
+type AudioPreset struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Channels     int32                  `protobuf:"varint,1,opt,name=Channels,proto3" json:"Channels,omitempty"`
+	Bitrate      int64                  `protobuf:"varint,2,opt,name=Bitrate,proto3" json:"Bitrate,omitempty"`
+	SampleRate   int64                  `protobuf:"varint,3,opt,name=SampleRate,proto3" json:"SampleRate,omitempty"`
+	PadBefore    float32                `protobuf:"fixed32,4,opt,name=PadBefore,proto3" json:"PadBefore,omitempty"`
+	PadAfter     float32                `protobuf:"fixed32,5,opt,name=PadAfter,proto3" json:"PadAfter,omitempty"`
+	TrimBefore   float32                `protobuf:"fixed32,6,opt,name=TrimBefore,proto3" json:"TrimBefore,omitempty"`
+	TrimDuration float32                `protobuf:"fixed32,7,opt,name=TrimDuration,proto3" json:"TrimDuration,omitempty"`
+	Loudness     *LoudnessPreset        `protobuf:"bytes,8,opt,name=Loudness,proto3" json:"Loudness,omitempty"` // nil if normalization is disabled
+	// outputs of one encoding run, single AAC-LC output
+	// with Channels/Bitrate/SampleRate above if empty
+	Renditions    []*AudioRendition `protobuf:"bytes,9,rep,name=Renditions,proto3" json:"Renditions,omitempty"`
+	ChannelLayout string            `protobuf:"bytes,10,opt,name=ChannelLayout,proto3" json:"ChannelLayout,omitempty"` // of the source, padding is generated with it
+	Downmix       *DownmixPreset    `protobuf:"bytes,11,opt,name=Downmix,proto3" json:"Downmix,omitempty"`             // stereo downmix of multichannel source, ffmpeg defaults if nil
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *AudioPreset) Reset() {
 	*x = AudioPreset{}
-	mi := &file_proto_composer_preset_proto_msgTypes[1]
+	mi := &file_proto_composer_preset_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -228,7 +476,7 @@ func (x *AudioPreset) String() string {
 func (*AudioPreset) ProtoMessage() {}
 
 func (x *AudioPreset) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_composer_preset_proto_msgTypes[1]
+	mi := &file_proto_composer_preset_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -241,7 +489,7 @@ func (x *AudioPreset) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AudioPreset.ProtoReflect.Descriptor instead.
 func (*AudioPreset) Descriptor() ([]byte, []int) {
-	return file_proto_composer_preset_proto_rawDescGZIP(), []int{1}
+	return file_proto_composer_preset_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *AudioPreset) GetChannels() int32 {
@@ -293,11 +541,371 @@ func (x *AudioPreset) GetTrimDuration() float32 {
 	return 0
 }
 
+func (x *AudioPreset) GetLoudness() *LoudnessPreset {
+	if x != nil {
+		return x.Loudness
+	}
+	return nil
+}
+
+func (x *AudioPreset) GetRenditions() []*AudioRendition {
+	if x != nil {
+		return x.Renditions
+	}
+	return nil
+}
+
+func (x *AudioPreset) GetChannelLayout() string {
+	if x != nil {
+		return x.ChannelLayout
+	}
+	return ""
+}
+
+func (x *AudioPreset) GetDownmix() *DownmixPreset {
+	if x != nil {
+		return x.Downmix
+	}
+	return nil
+}
+
+// levels are linear gains of channels mixed into left/right
+type DownmixPreset struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Center        float32                `protobuf:"fixed32,1,opt,name=Center,proto3" json:"Center,omitempty"`
+	Surround      float32                `protobuf:"fixed32,2,opt,name=Surround,proto3" json:"Surround,omitempty"`
+	LFE           float32                `protobuf:"fixed32,3,opt,name=LFE,proto3" json:"LFE,omitempty"`
+	Encoding      string                 `protobuf:"bytes,4,opt,name=Encoding,proto3" json:"Encoding,omitempty"` // dolby, dplii, none if empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownmixPreset) Reset() {
+	*x = DownmixPreset{}
+	mi := &file_proto_composer_preset_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownmixPreset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownmixPreset) ProtoMessage() {}
+
+func (x *DownmixPreset) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_composer_preset_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownmixPreset.ProtoReflect.Descriptor instead.
+func (*DownmixPreset) Descriptor() ([]byte, []int) {
+	return file_proto_composer_preset_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DownmixPreset) GetCenter() float32 {
+	if x != nil {
+		return x.Center
+	}
+	return 0
+}
+
+func (x *DownmixPreset) GetSurround() float32 {
+	if x != nil {
+		return x.Surround
+	}
+	return 0
+}
+
+func (x *DownmixPreset) GetLFE() float32 {
+	if x != nil {
+		return x.LFE
+	}
+	return 0
+}
+
+func (x *DownmixPreset) GetEncoding() string {
+	if x != nil {
+		return x.Encoding
+	}
+	return ""
+}
+
+type AudioRendition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`   // unique within a track, part of the output file name
+	Codec         string                 `protobuf:"bytes,2,opt,name=Codec,proto3" json:"Codec,omitempty"` // aac, he-aac, opus, eac3
+	Bitrate       int64                  `protobuf:"varint,3,opt,name=Bitrate,proto3" json:"Bitrate,omitempty"`
+	Channels      int32                  `protobuf:"varint,4,opt,name=Channels,proto3" json:"Channels,omitempty"`
+	SampleRate    int64                  `protobuf:"varint,5,opt,name=SampleRate,proto3" json:"SampleRate,omitempty"`
+	ChannelLayout string                 `protobuf:"bytes,6,opt,name=ChannelLayout,proto3" json:"ChannelLayout,omitempty"` // kept multichannel layout, empty for mono/stereo
+	Loudness      *LoudnessPreset        `protobuf:"bytes,7,opt,name=Loudness,proto3" json:"Loudness,omitempty"`           // measured on the downmix of multichannel source, mono/stereo only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AudioRendition) Reset() {
+	*x = AudioRendition{}
+	mi := &file_proto_composer_preset_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AudioRendition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AudioRendition) ProtoMessage() {}
+
+func (x *AudioRendition) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_composer_preset_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AudioRendition.ProtoReflect.Descriptor instead.
+func (*AudioRendition) Descriptor() ([]byte, []int) {
+	return file_proto_composer_preset_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AudioRendition) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AudioRendition) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *AudioRendition) GetBitrate() int64 {
+	if x != nil {
+		return x.Bitrate
+	}
+	return 0
+}
+
+func (x *AudioRendition) GetChannels() int32 {
+	if x != nil {
+		return x.Channels
+	}
+	return 0
+}
+
+func (x *AudioRendition) GetSampleRate() int64 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *AudioRendition) GetChannelLayout() string {
+	if x != nil {
+		return x.ChannelLayout
+	}
+	return ""
+}
+
+func (x *AudioRendition) GetLoudness() *LoudnessPreset {
+	if x != nil {
+		return x.Loudness
+	}
+	return nil
+}
+
+// EBU R128 normalization, targets from Settings.Loudness,
+// measured values from the first loudnorm pass in splitter
+type LoudnessPreset struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TargetI        float32                `protobuf:"fixed32,1,opt,name=TargetI,proto3" json:"TargetI,omitempty"`     // integrated loudness, LUFS
+	TargetTP       float32                `protobuf:"fixed32,2,opt,name=TargetTP,proto3" json:"TargetTP,omitempty"`   // true peak, dBTP
+	TargetLRA      float32                `protobuf:"fixed32,3,opt,name=TargetLRA,proto3" json:"TargetLRA,omitempty"` // loudness range, LU
+	MeasuredI      float32                `protobuf:"fixed32,4,opt,name=MeasuredI,proto3" json:"MeasuredI,omitempty"`
+	MeasuredTP     float32                `protobuf:"fixed32,5,opt,name=MeasuredTP,proto3" json:"MeasuredTP,omitempty"`
+	MeasuredLRA    float32                `protobuf:"fixed32,6,opt,name=MeasuredLRA,proto3" json:"MeasuredLRA,omitempty"`
+	MeasuredThresh float32                `protobuf:"fixed32,7,opt,name=MeasuredThresh,proto3" json:"MeasuredThresh,omitempty"`
+	Offset         float32                `protobuf:"fixed32,8,opt,name=Offset,proto3" json:"Offset,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *LoudnessPreset) Reset() {
+	*x = LoudnessPreset{}
+	mi := &file_proto_composer_preset_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoudnessPreset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoudnessPreset) ProtoMessage() {}
+
+func (x *LoudnessPreset) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_composer_preset_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoudnessPreset.ProtoReflect.Descriptor instead.
+func (*LoudnessPreset) Descriptor() ([]byte, []int) {
+	return file_proto_composer_preset_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LoudnessPreset) GetTargetI() float32 {
+	if x != nil {
+		return x.TargetI
+	}
+	return 0
+}
+
+func (x *LoudnessPreset) GetTargetTP() float32 {
+	if x != nil {
+		return x.TargetTP
+	}
+	return 0
+}
+
+func (x *LoudnessPreset) GetTargetLRA() float32 {
+	if x != nil {
+		return x.TargetLRA
+	}
+	return 0
+}
+
+func (x *LoudnessPreset) GetMeasuredI() float32 {
+	if x != nil {
+		return x.MeasuredI
+	}
+	return 0
+}
+
+func (x *LoudnessPreset) GetMeasuredTP() float32 {
+	if x != nil {
+		return x.MeasuredTP
+	}
+	return 0
+}
+
+func (x *LoudnessPreset) GetMeasuredLRA() float32 {
+	if x != nil {
+		return x.MeasuredLRA
+	}
+	return 0
+}
+
+func (x *LoudnessPreset) GetMeasuredThresh() float32 {
+	if x != nil {
+		return x.MeasuredThresh
+	}
+	return 0
+}
+
+func (x *LoudnessPreset) GetOffset() float32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+// thumbnails strip of a single chunk, see Settings.Thumbnails
+type ThumbnailsPreset struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Interval      float32                `protobuf:"fixed32,1,opt,name=Interval,proto3" json:"Interval,omitempty"` // seconds between thumbnails
+	Offset        float32                `protobuf:"fixed32,2,opt,name=Offset,proto3" json:"Offset,omitempty"`     // first thumbnail time relative to chunk start
+	Count         int32                  `protobuf:"varint,3,opt,name=Count,proto3" json:"Count,omitempty"`        // thumbnails in chunk
+	Width         int32                  `protobuf:"varint,4,opt,name=Width,proto3" json:"Width,omitempty"`        // thumbnail width, height keeps aspect ratio
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ThumbnailsPreset) Reset() {
+	*x = ThumbnailsPreset{}
+	mi := &file_proto_composer_preset_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ThumbnailsPreset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThumbnailsPreset) ProtoMessage() {}
+
+func (x *ThumbnailsPreset) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_composer_preset_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThumbnailsPreset.ProtoReflect.Descriptor instead.
+func (*ThumbnailsPreset) Descriptor() ([]byte, []int) {
+	return file_proto_composer_preset_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ThumbnailsPreset) GetInterval() float32 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+func (x *ThumbnailsPreset) GetOffset() float32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ThumbnailsPreset) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ThumbnailsPreset) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
 var File_proto_composer_preset_proto protoreflect.FileDescriptor
 
 const file_proto_composer_preset_proto_rawDesc = "" +
 	"\n" +
-	"\x1bproto/composer/preset.proto\x12\bcomposer\"\xea\x03\n" +
+	"\x1bproto/composer/preset.proto\x12\bcomposer\"\xe2\x05\n" +
 	"\x06Preset\x12\x18\n" +
 	"\aQuality\x18\x01 \x01(\tR\aQuality\x12\x1e\n" +
 	"\n" +
@@ -327,7 +935,33 @@ const file_proto_composer_preset_proto_rawDesc = "" +
 	"IsVertical\x18\x10 \x01(\bR\n" +
 	"IsVertical\x12\x14\n" +
 	"\x05Width\x18\x11 \x01(\x05R\x05Width\x12\x16\n" +
-	"\x06Height\x18\x12 \x01(\x05R\x06Height\"\xe1\x01\n" +
+	"\x06Height\x18\x12 \x01(\x05R\x06Height\x12\x18\n" +
+	"\aTonemap\x18\x13 \x01(\tR\aTonemap\x12\x10\n" +
+	"\x03HDR\x18\x14 \x01(\bR\x03HDR\x123\n" +
+	"\tMastering\x18\x15 \x01(\v2\x15.composer.HDRMetadataR\tMastering\x12*\n" +
+	"\x10SourceColorSpace\x18\x16 \x01(\tR\x10SourceColorSpace\x12*\n" +
+	"\x10SourceColorRange\x18\x17 \x01(\tR\x10SourceColorRange\x12=\n" +
+	"\vDeinterlace\x18\x18 \x01(\v2\x1b.composer.DeinterlacePresetR\vDeinterlace\"i\n" +
+	"\x11DeinterlacePreset\x12\x16\n" +
+	"\x06Filter\x18\x01 \x01(\tR\x06Filter\x12\x1e\n" +
+	"\n" +
+	"FieldOrder\x18\x02 \x01(\tR\n" +
+	"FieldOrder\x12\x1c\n" +
+	"\tFieldRate\x18\x03 \x01(\bR\tFieldRate\"\xbb\x02\n" +
+	"\vHDRMetadata\x12\x12\n" +
+	"\x04RedX\x18\x01 \x01(\x02R\x04RedX\x12\x12\n" +
+	"\x04RedY\x18\x02 \x01(\x02R\x04RedY\x12\x16\n" +
+	"\x06GreenX\x18\x03 \x01(\x02R\x06GreenX\x12\x16\n" +
+	"\x06GreenY\x18\x04 \x01(\x02R\x06GreenY\x12\x14\n" +
+	"\x05BlueX\x18\x05 \x01(\x02R\x05BlueX\x12\x14\n" +
+	"\x05BlueY\x18\x06 \x01(\x02R\x05BlueY\x12\x16\n" +
+	"\x06WhiteX\x18\a \x01(\x02R\x06WhiteX\x12\x16\n" +
+	"\x06WhiteY\x18\b \x01(\x02R\x06WhiteY\x12\"\n" +
+	"\fMaxLuminance\x18\t \x01(\x02R\fMaxLuminance\x12\"\n" +
+	"\fMinLuminance\x18\n" +
+	" \x01(\x02R\fMinLuminance\x12\x16\n" +
+	"\x06MaxCLL\x18\v \x01(\x05R\x06MaxCLL\x12\x18\n" +
+	"\aMaxFALL\x18\f \x01(\x05R\aMaxFALL\"\xaa\x03\n" +
 	"\vAudioPreset\x12\x1a\n" +
 	"\bChannels\x18\x01 \x01(\x05R\bChannels\x12\x18\n" +
 	"\aBitrate\x18\x02 \x01(\x03R\aBitrate\x12\x1e\n" +
@@ -339,7 +973,45 @@ const file_proto_composer_preset_proto_rawDesc = "" +
 	"\n" +
 	"TrimBefore\x18\x06 \x01(\x02R\n" +
 	"TrimBefore\x12\"\n" +
-	"\fTrimDuration\x18\a \x01(\x02R\fTrimDurationB0Z.github.com/timohahaa/transcoder/proto/composerb\x06proto3"
+	"\fTrimDuration\x18\a \x01(\x02R\fTrimDuration\x124\n" +
+	"\bLoudness\x18\b \x01(\v2\x18.composer.LoudnessPresetR\bLoudness\x128\n" +
+	"\n" +
+	"Renditions\x18\t \x03(\v2\x18.composer.AudioRenditionR\n" +
+	"Renditions\x12$\n" +
+	"\rChannelLayout\x18\n" +
+	" \x01(\tR\rChannelLayout\x121\n" +
+	"\aDownmix\x18\v \x01(\v2\x17.composer.DownmixPresetR\aDownmix\"q\n" +
+	"\rDownmixPreset\x12\x16\n" +
+	"\x06Center\x18\x01 \x01(\x02R\x06Center\x12\x1a\n" +
+	"\bSurround\x18\x02 \x01(\x02R\bSurround\x12\x10\n" +
+	"\x03LFE\x18\x03 \x01(\x02R\x03LFE\x12\x1a\n" +
+	"\bEncoding\x18\x04 \x01(\tR\bEncoding\"\xec\x01\n" +
+	"\x0eAudioRendition\x12\x12\n" +
+	"\x04Name\x18\x01 \x01(\tR\x04Name\x12\x14\n" +
+	"\x05Codec\x18\x02 \x01(\tR\x05Codec\x12\x18\n" +
+	"\aBitrate\x18\x03 \x01(\x03R\aBitrate\x12\x1a\n" +
+	"\bChannels\x18\x04 \x01(\x05R\bChannels\x12\x1e\n" +
+	"\n" +
+	"SampleRate\x18\x05 \x01(\x03R\n" +
+	"SampleRate\x12$\n" +
+	"\rChannelLayout\x18\x06 \x01(\tR\rChannelLayout\x124\n" +
+	"\bLoudness\x18\a \x01(\v2\x18.composer.LoudnessPresetR\bLoudness\"\x84\x02\n" +
+	"\x0eLoudnessPreset\x12\x18\n" +
+	"\aTargetI\x18\x01 \x01(\x02R\aTargetI\x12\x1a\n" +
+	"\bTargetTP\x18\x02 \x01(\x02R\bTargetTP\x12\x1c\n" +
+	"\tTargetLRA\x18\x03 \x01(\x02R\tTargetLRA\x12\x1c\n" +
+	"\tMeasuredI\x18\x04 \x01(\x02R\tMeasuredI\x12\x1e\n" +
+	"\n" +
+	"MeasuredTP\x18\x05 \x01(\x02R\n" +
+	"MeasuredTP\x12 \n" +
+	"\vMeasuredLRA\x18\x06 \x01(\x02R\vMeasuredLRA\x12&\n" +
+	"\x0eMeasuredThresh\x18\a \x01(\x02R\x0eMeasuredThresh\x12\x16\n" +
+	"\x06Offset\x18\b \x01(\x02R\x06Offset\"r\n" +
+	"\x10ThumbnailsPreset\x12\x1a\n" +
+	"\bInterval\x18\x01 \x01(\x02R\bInterval\x12\x16\n" +
+	"\x06Offset\x18\x02 \x01(\x02R\x06Offset\x12\x14\n" +
+	"\x05Count\x18\x03 \x01(\x05R\x05Count\x12\x14\n" +
+	"\x05Width\x18\x04 \x01(\x05R\x05WidthB0Z.github.com/timohahaa/transcoder/proto/composerb\x06proto3"
 
 var (
 	file_proto_composer_preset_proto_rawDescOnce sync.Once
@@ -353,17 +1025,29 @@ func file_proto_composer_preset_proto_rawDescGZIP() []byte {
 	return file_proto_composer_preset_proto_rawDescData
 }
 
-var file_proto_composer_preset_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_composer_preset_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_proto_composer_preset_proto_goTypes = []any{
-	(*Preset)(nil),      // 0: composer.Preset
-	(*AudioPreset)(nil), // 1: composer.AudioPreset
+	(*Preset)(nil),            // 0: composer.Preset
+	(*DeinterlacePreset)(nil), // 1: composer.DeinterlacePreset
+	(*HDRMetadata)(nil),       // 2: composer.HDRMetadata
+	(*AudioPreset)(nil),       // 3: composer.AudioPreset
+	(*DownmixPreset)(nil),     // 4: composer.DownmixPreset
+	(*AudioRendition)(nil),    // 5: composer.AudioRendition
+	(*LoudnessPreset)(nil),    // 6: composer.LoudnessPreset
+	(*ThumbnailsPreset)(nil),  // 7: composer.ThumbnailsPreset
 }
 var file_proto_composer_preset_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2, // 0: composer.Preset.Mastering:type_name -> composer.HDRMetadata
+	1, // 1: composer.Preset.Deinterlace:type_name -> composer.DeinterlacePreset
+	6, // 2: composer.AudioPreset.Loudness:type_name -> composer.LoudnessPreset
+	5, // 3: composer.AudioPreset.Renditions:type_name -> composer.AudioRendition
+	4, // 4: composer.AudioPreset.Downmix:type_name -> composer.DownmixPreset
+	6, // 5: composer.AudioRendition.Loudness:type_name -> composer.LoudnessPreset
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_proto_composer_preset_proto_init() }
@@ -377,7 +1061,7 @@ func file_proto_composer_preset_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_composer_preset_proto_rawDesc), len(file_proto_composer_preset_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -137,6 +137,11 @@ type Audio struct {
 	Duration      float32                `protobuf:"fixed32,3,opt,name=Duration,proto3" json:"Duration,omitempty"`
 	TrackNum      int32                  `protobuf:"varint,4,opt,name=TrackNum,proto3" json:"TrackNum,omitempty"`
 	Preset        *AudioPreset           `protobuf:"bytes,5,opt,name=Preset,proto3" json:"Preset,omitempty"`
+	Language      string                 `protobuf:"bytes,6,opt,name=Language,proto3" json:"Language,omitempty"` // ISO 639 as tagged in the source, empty if unknown
+	Title         string                 `protobuf:"bytes,7,opt,name=Title,proto3" json:"Title,omitempty"`
+	Default       bool                   `protobuf:"varint,8,opt,name=Default,proto3" json:"Default,omitempty"` // exactly one track of a task is default
+	Forced        bool                   `protobuf:"varint,9,opt,name=Forced,proto3" json:"Forced,omitempty"`
+	StreamIndex   int32                  `protobuf:"varint,10,opt,name=StreamIndex,proto3" json:"StreamIndex,omitempty"` // in the source file
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -206,6 +211,41 @@ func (x *Audio) GetPreset() *AudioPreset {
 	return nil
 }
 
+func (x *Audio) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Audio) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Audio) GetDefault() bool {
+	if x != nil {
+		return x.Default
+	}
+	return false
+}
+
+func (x *Audio) GetForced() bool {
+	if x != nil {
+		return x.Forced
+	}
+	return false
+}
+
+func (x *Audio) GetStreamIndex() int32 {
+	if x != nil {
+		return x.StreamIndex
+	}
+	return 0
+}
+
 type Video struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Codec         string                 `protobuf:"bytes,1,opt,name=Codec,proto3" json:"Codec,omitempty"`
@@ -215,6 +255,10 @@ type Video struct {
 	PixFmt        string                 `protobuf:"bytes,5,opt,name=PixFmt,proto3" json:"PixFmt,omitempty"`
 	CreatePoster  bool                   `protobuf:"varint,6,opt,name=CreatePoster,proto3" json:"CreatePoster,omitempty"`
 	Presets       []*Preset              `protobuf:"bytes,7,rep,name=Presets,proto3" json:"Presets,omitempty"`
+	Thumbnails    *ThumbnailsPreset      `protobuf:"bytes,8,opt,name=Thumbnails,proto3" json:"Thumbnails,omitempty"` // nil if no thumbnails needed
+	Metrics       bool                   `protobuf:"varint,9,opt,name=Metrics,proto3" json:"Metrics,omitempty"`      // score renditions against the source chunk
+	Gate          *QualityGate           `protobuf:"bytes,10,opt,name=Gate,proto3" json:"Gate,omitempty"`            // nil if chunks are accepted whatever their scores
+	Start         float64                `protobuf:"fixed64,11,opt,name=Start,proto3" json:"Start,omitempty"`        // seconds, of the chunk on the source timeline
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -298,6 +342,103 @@ func (x *Video) GetPresets() []*Preset {
 	return nil
 }
 
+func (x *Video) GetThumbnails() *ThumbnailsPreset {
+	if x != nil {
+		return x.Thumbnails
+	}
+	return nil
+}
+
+func (x *Video) GetMetrics() bool {
+	if x != nil {
+		return x.Metrics
+	}
+	return false
+}
+
+func (x *Video) GetGate() *QualityGate {
+	if x != nil {
+		return x.Gate
+	}
+	return nil
+}
+
+func (x *Video) GetStart() float64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+// chunk is re-encoded while a rendition scores below the floor
+type QualityGate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinVMAF       float32                `protobuf:"fixed32,1,opt,name=MinVMAF,proto3" json:"MinVMAF,omitempty"` // of the chunk mean
+	MaxRetries    int32                  `protobuf:"varint,2,opt,name=MaxRetries,proto3" json:"MaxRetries,omitempty"`
+	Retry         int32                  `protobuf:"varint,3,opt,name=Retry,proto3" json:"Retry,omitempty"`          // 0 for the first encode
+	Renditions    []string               `protobuf:"bytes,4,rep,name=Renditions,proto3" json:"Renditions,omitempty"` // to re-encode on retry, all presets if empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QualityGate) Reset() {
+	*x = QualityGate{}
+	mi := &file_proto_composer_task_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QualityGate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QualityGate) ProtoMessage() {}
+
+func (x *QualityGate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_composer_task_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QualityGate.ProtoReflect.Descriptor instead.
+func (*QualityGate) Descriptor() ([]byte, []int) {
+	return file_proto_composer_task_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *QualityGate) GetMinVMAF() float32 {
+	if x != nil {
+		return x.MinVMAF
+	}
+	return 0
+}
+
+func (x *QualityGate) GetMaxRetries() int32 {
+	if x != nil {
+		return x.MaxRetries
+	}
+	return 0
+}
+
+func (x *QualityGate) GetRetry() int32 {
+	if x != nil {
+		return x.Retry
+	}
+	return 0
+}
+
+func (x *QualityGate) GetRenditions() []string {
+	if x != nil {
+		return x.Renditions
+	}
+	return nil
+}
+
 var File_proto_composer_task_proto protoreflect.FileDescriptor
 
 const file_proto_composer_task_proto_rawDesc = "" +
@@ -317,13 +458,19 @@ const file_proto_composer_task_proto_rawDesc = "" +
 	"\bFeatures\x18\t \x03(\v2\x1c.composer.Task.FeaturesEntryR\bFeatures\x1a;\n" +
 	"\rFeaturesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\bR\x05value:\x028\x01\"\x9e\x01\n" +
+	"\x05value\x18\x02 \x01(\bR\x05value:\x028\x01\"\xa4\x02\n" +
 	"\x05Audio\x12\x14\n" +
 	"\x05Codec\x18\x01 \x01(\tR\x05Codec\x12\x18\n" +
 	"\aBitRate\x18\x02 \x01(\x03R\aBitRate\x12\x1a\n" +
 	"\bDuration\x18\x03 \x01(\x02R\bDuration\x12\x1a\n" +
 	"\bTrackNum\x18\x04 \x01(\x05R\bTrackNum\x12-\n" +
-	"\x06Preset\x18\x05 \x01(\v2\x15.composer.AudioPresetR\x06Preset\"\xd5\x01\n" +
+	"\x06Preset\x18\x05 \x01(\v2\x15.composer.AudioPresetR\x06Preset\x12\x1a\n" +
+	"\bLanguage\x18\x06 \x01(\tR\bLanguage\x12\x14\n" +
+	"\x05Title\x18\a \x01(\tR\x05Title\x12\x18\n" +
+	"\aDefault\x18\b \x01(\bR\aDefault\x12\x16\n" +
+	"\x06Forced\x18\t \x01(\bR\x06Forced\x12 \n" +
+	"\vStreamIndex\x18\n" +
+	" \x01(\x05R\vStreamIndex\"\xec\x02\n" +
 	"\x05Video\x12\x14\n" +
 	"\x05Codec\x18\x01 \x01(\tR\x05Codec\x12\x18\n" +
 	"\aBitRate\x18\x02 \x01(\x03R\aBitRate\x12\x1a\n" +
@@ -331,7 +478,23 @@ const file_proto_composer_task_proto_rawDesc = "" +
 	"\aQuality\x18\x04 \x01(\tR\aQuality\x12\x16\n" +
 	"\x06PixFmt\x18\x05 \x01(\tR\x06PixFmt\x12\"\n" +
 	"\fCreatePoster\x18\x06 \x01(\bR\fCreatePoster\x12*\n" +
-	"\aPresets\x18\a \x03(\v2\x10.composer.PresetR\aPresetsB0Z.github.com/timohahaa/transcoder/proto/composerb\x06proto3"
+	"\aPresets\x18\a \x03(\v2\x10.composer.PresetR\aPresets\x12:\n" +
+	"\n" +
+	"Thumbnails\x18\b \x01(\v2\x1a.composer.ThumbnailsPresetR\n" +
+	"Thumbnails\x12\x18\n" +
+	"\aMetrics\x18\t \x01(\bR\aMetrics\x12)\n" +
+	"\x04Gate\x18\n" +
+	" \x01(\v2\x15.composer.QualityGateR\x04Gate\x12\x14\n" +
+	"\x05Start\x18\v \x01(\x01R\x05Start\"}\n" +
+	"\vQualityGate\x12\x18\n" +
+	"\aMinVMAF\x18\x01 \x01(\x02R\aMinVMAF\x12\x1e\n" +
+	"\n" +
+	"MaxRetries\x18\x02 \x01(\x05R\n" +
+	"MaxRetries\x12\x14\n" +
+	"\x05Retry\x18\x03 \x01(\x05R\x05Retry\x12\x1e\n" +
+	"\n" +
+	"Renditions\x18\x04 \x03(\tR\n" +
+	"RenditionsB0Z.github.com/timohahaa/transcoder/proto/composerb\x06proto3"
 
 var (
 	file_proto_composer_task_proto_rawDescOnce sync.Once
@@ -345,28 +508,32 @@ func file_proto_composer_task_proto_rawDescGZIP() []byte {
 	return file_proto_composer_task_proto_rawDescData
 }
 
-var file_proto_composer_task_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_composer_task_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_proto_composer_task_proto_goTypes = []any{
 	(*Task)(nil),                  // 0: composer.Task
 	(*Audio)(nil),                 // 1: composer.Audio
 	(*Video)(nil),                 // 2: composer.Video
-	nil,                           // 3: composer.Task.FeaturesEntry
-	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
-	(*AudioPreset)(nil),           // 5: composer.AudioPreset
-	(*Preset)(nil),                // 6: composer.Preset
+	(*QualityGate)(nil),           // 3: composer.QualityGate
+	nil,                           // 4: composer.Task.FeaturesEntry
+	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
+	(*AudioPreset)(nil),           // 6: composer.AudioPreset
+	(*Preset)(nil),                // 7: composer.Preset
+	(*ThumbnailsPreset)(nil),      // 8: composer.ThumbnailsPreset
 }
 var file_proto_composer_task_proto_depIdxs = []int32{
 	2, // 0: composer.Task.Video:type_name -> composer.Video
 	1, // 1: composer.Task.Audio:type_name -> composer.Audio
-	4, // 2: composer.Task.CreatedAt:type_name -> google.protobuf.Timestamp
-	3, // 3: composer.Task.Features:type_name -> composer.Task.FeaturesEntry
-	5, // 4: composer.Audio.Preset:type_name -> composer.AudioPreset
-	6, // 5: composer.Video.Presets:type_name -> composer.Preset
-	6, // [6:6] is the sub-list for method output_type
-	6, // [6:6] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	5, // 2: composer.Task.CreatedAt:type_name -> google.protobuf.Timestamp
+	4, // 3: composer.Task.Features:type_name -> composer.Task.FeaturesEntry
+	6, // 4: composer.Audio.Preset:type_name -> composer.AudioPreset
+	7, // 5: composer.Video.Presets:type_name -> composer.Preset
+	8, // 6: composer.Video.Thumbnails:type_name -> composer.ThumbnailsPreset
+	3, // 7: composer.Video.Gate:type_name -> composer.QualityGate
+	8, // [8:8] is the sub-list for method output_type
+	8, // [8:8] is the sub-list for method input_type
+	8, // [8:8] is the sub-list for extension type_name
+	8, // [8:8] is the sub-list for extension extendee
+	0, // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_proto_composer_task_proto_init() }
@@ -381,7 +548,7 @@ func file_proto_composer_task_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_composer_task_proto_rawDesc), len(file_proto_composer_task_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   0,
 		},